@@ -0,0 +1,97 @@
+package ghttp
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// JSONBatchHandlerFunc processes a single item of a batch. It is called
+// concurrently for the items of a request, and should stop early once the
+// request context is done.
+type JSONBatchHandlerFunc[I any, O any] func(*http.Request, I) O
+
+// JSONBatchHandler decodes a JSON array of I, processes the items
+// concurrently and responds with the array of results in the same order. Use
+// WithBatchConcurrency to limit how many items are processed at once.
+type JSONBatchHandler[I any, O any] struct {
+	handlerFunc JSONBatchHandlerFunc[I, O]
+	opts        handlerOptions
+}
+
+func NewJSONBatchHandler[I any, O any](fn JSONBatchHandlerFunc[I, O], opts ...HandlerOption) JSONBatchHandler[I, O] {
+	return JSONBatchHandler[I, O]{
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+}
+
+func (h JSONBatchHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONBatchHandler[I, O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload []I
+	if err := h.opts.decodeBody(w, r, &payload); err != nil {
+		h.opts.writeDecodeError(w, err)
+		return
+	}
+	results, ok := h.process(r, payload)
+	if r.Context().Err() != nil {
+		// The client is gone, so write no response.
+		return
+	}
+	if !ok {
+		h.opts.writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	h.opts.writeJSON(w, http.StatusOK, results)
+}
+
+// process runs the handler function for each of items, starting no more once
+// the request context is done. It reports false if any of them panicked; the
+// panic is recovered and logged, as it happens off the request goroutine.
+func (h JSONBatchHandler[I, O]) process(r *http.Request, items []I) ([]O, bool) {
+	concurrency := h.opts.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	results := make([]O, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var panicked atomic.Bool
+start:
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-r.Context().Done():
+			break start
+		}
+		wg.Add(1)
+		go func(i int, item I) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					Logger().Error("batch item panic", slog.Int("index", i), slog.Any("panic", rec), slog.String("stack", string(debug.Stack())))
+					panicked.Store(true)
+				}
+				<-sem
+				wg.Done()
+			}()
+			results[i] = h.handlerFunc(r, item)
+		}(i, item)
+	}
+	wg.Wait()
+	return results, !panicked.Load()
+}
+
+func (h JSONBatchHandler[I, O]) PayloadType() reflect.Type {
+	return reflect.TypeOf((*[]I)(nil)).Elem()
+}
+
+func (h JSONBatchHandler[I, O]) ResponseType() reflect.Type {
+	return reflect.TypeOf((*[]O)(nil)).Elem()
+}
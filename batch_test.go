@@ -0,0 +1,78 @@
+package ghttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func serveBatch(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return rec
+}
+
+func TestJSONBatchHandlerKeepsOrder(t *testing.T) {
+	h := NewJSONBatchHandler(func(r *http.Request, n int) int {
+		// Later items finish first.
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		return n * n
+	})
+
+	rec := serveBatch(t, h, `[1, 2, 3, 4, 5]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got []int
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 4, 9, 16, 25}; !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+}
+
+func TestJSONBatchHandlerLimitsConcurrency(t *testing.T) {
+	const limit = 2
+	var running, peak atomic.Int32
+	h := NewJSONBatchHandler(func(r *http.Request, n int) int {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if current <= p || peak.CompareAndSwap(p, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return n
+	}, WithBatchConcurrency(limit))
+
+	rec := serveBatch(t, h, `[1, 2, 3, 4, 5, 6, 7, 8]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := peak.Load(); got > limit {
+		t.Errorf("%d items processed at once, want at most %d", got, limit)
+	}
+}
+
+func TestJSONBatchHandlerRecoversItemPanics(t *testing.T) {
+	h := NewJSONBatchHandler(func(r *http.Request, n int) int {
+		if n == 2 {
+			panic("bad item")
+		}
+		return n
+	})
+
+	rec := serveBatch(t, h, `[1, 2, 3]`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
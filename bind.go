@@ -0,0 +1,187 @@
+package ghttp
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// QueryParamTyper is implemented by handlers that read their query string
+// into a struct, so each of its fields can be documented as a parameter.
+type QueryParamTyper interface {
+	QueryParamType() reflect.Type
+}
+
+// PathParamTags are the struct tags naming path parameter fields, in the
+// order they are looked up, both when documenting a PathParamTyper and when
+// binding with the chi package's PathParams.
+var PathParamTags = []string{"path", "chi"}
+
+// PathParamTyper is implemented by handlers that describe their path
+// parameters with a struct. Fields are matched to the route's path parameters
+// by their PathParamTags, falling back to the lowercased field name.
+type PathParamTyper interface {
+	PathParamType() reflect.Type
+}
+
+// Enumer is implemented by parameter types with a finite set of values, so
+// they can be documented as an enum.
+type Enumer interface {
+	Enum() []interface{}
+}
+
+// EnumValuer is implemented by types with a finite set of values, such as
+// string constants, so their schema lists them as an enum. EnumValues is
+// called on the zero value.
+type EnumValuer interface {
+	EnumValues() []interface{}
+}
+
+// UnionTyper is implemented by types whose values are one of several types,
+// such as a payment method that is either a card or a bank transfer. oneOf
+// reports whether exactly one of them matches (`oneOf`) rather than any
+// number (`anyOf`). UnionTypes is called on the zero value.
+type UnionTyper interface {
+	UnionTypes() (types []reflect.Type, oneOf bool)
+}
+
+// QueryBind populates the struct T from the query string of r. Fields are
+// matched by their `query` tag, falling back to the lowercased field name.
+func QueryBind[T any](r *http.Request) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() != reflect.Struct {
+		return v, fmt.Errorf("binding query: %s is not a struct", rv.Type())
+	}
+	query := r.URL.Query()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		name := ParamName(f, "query")
+		if name == "" || !query.Has(name) {
+			continue
+		}
+		if err := setValue(rv.Field(i), query.Get(name)); err != nil {
+			return v, fmt.Errorf("binding query parameter %q: %w", name, err)
+		}
+	}
+	return v, nil
+}
+
+// BindParams populates the struct T with the parameters lookup returns, along
+// with whether each is present. Fields are matched by the first of tags they
+// have, falling back to the lowercased field name, and converted as by
+// QueryBind. Errors name the offending field.
+func BindParams[T any](lookup func(name string) (string, bool), tags ...string) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() != reflect.Struct {
+		return v, fmt.Errorf("binding parameters: %s is not a struct", rv.Type())
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		name := ParamName(f, tags...)
+		if name == "" {
+			continue
+		}
+		s, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setValue(rv.Field(i), s); err != nil {
+			return v, fmt.Errorf("binding field %s from parameter %q: %w", f.Name, name, err)
+		}
+	}
+	return v, nil
+}
+
+// ParseParam converts the parameter value s to T, as QueryBind does for each
+// field.
+func ParseParam[T any](s string) (T, error) {
+	var v T
+	err := setValue(reflect.ValueOf(&v).Elem(), s)
+	return v, err
+}
+
+// ParamName returns the parameter name of f from the first of the given
+// struct tags it has, falling back to the lowercased field name. It returns
+// "" for unexported fields and fields tagged "-".
+func ParamName(f reflect.StructField, tags ...string) string {
+	if !f.IsExported() {
+		return ""
+	}
+	var name string
+	for _, tag := range tags {
+		if value, ok := f.Tag.Lookup(tag); ok {
+			name = strings.Split(value, ",")[0]
+			break
+		}
+	}
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return strings.ToLower(f.Name)
+	default:
+		return name
+	}
+}
+
+// setValue converts s to the type of v and stores it. time.Time values are
+// parsed as RFC 3339, and types implementing encoding.TextUnmarshaler (such
+// as uuid.UUID) parse themselves.
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
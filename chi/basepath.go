@@ -0,0 +1,73 @@
+package chi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// docBasePath returns the basePath documented for r: cfg.BasePath, or the
+// prefix shared by every route when cfg.StripBasePath is set.
+func docBasePath(r chi.Router, cfg Config) string {
+	if cfg.BasePath != "" {
+		return strings.TrimSuffix(cfg.BasePath, "/")
+	}
+	if !cfg.StripBasePath {
+		return ""
+	}
+	var routes []string
+	chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if _, ok := handler.(docsHandler); !ok {
+			routes = append(routes, route)
+		}
+		return nil
+	})
+	return commonPrefix(routes)
+}
+
+// relativeRoute returns route relative to basePath, or route itself when it
+// is not under it.
+func relativeRoute(route string, basePath string) string {
+	if basePath == "" || (route != basePath && !strings.HasPrefix(route, basePath+"/")) {
+		return route
+	}
+	route = strings.TrimPrefix(route, basePath)
+	if route == "" {
+		route = "/"
+	}
+	return route
+}
+
+// commonPrefix returns the leading path segments shared by all routes, or ""
+// if there are none. Segments with path parameters, and the final segment of
+// a route, are never shared, so no route is reduced to its prefix alone.
+func commonPrefix(routes []string) string {
+	var prefix []string
+	first := true
+	for _, route := range routes {
+		segments := strings.Split(strings.Trim(route, "/"), "/")
+		if !strings.HasSuffix(route, "/") {
+			segments = segments[:len(segments)-1]
+		}
+		if first {
+			prefix, first = segments, false
+			continue
+		}
+		n := 0
+		for n < len(prefix) && n < len(segments) && prefix[n] == segments[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	for i, segment := range prefix {
+		if segment == "" || strings.Contains(segment, "{") {
+			prefix = prefix[:i]
+			break
+		}
+	}
+	if len(prefix) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(prefix, "/")
+}
@@ -0,0 +1,78 @@
+package chi
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-openapi/spec"
+)
+
+// RouteRegistry lets the code that registers routes tell a cached document
+// that it is out of date. Pass it to WithRouteRegistry and call Invalidate
+// after adding a route.
+type RouteRegistry struct {
+	generation atomic.Uint64
+}
+
+func NewRouteRegistry() *RouteRegistry {
+	return &RouteRegistry{}
+}
+
+// Invalidate makes the next request rebuild the document instead of serving
+// the cached one.
+func (rr *RouteRegistry) Invalidate() {
+	rr.generation.Add(1)
+}
+
+func (rr *RouteRegistry) current() uint64 {
+	if rr == nil {
+		return 0
+	}
+	return rr.generation.Load()
+}
+
+// specCache serves a built document for ttl, then keeps serving it while a
+// fresh one is built in the background (stale-while-revalidate). With
+// blocking set, the request after ttl waits for the fresh one instead.
+type specCache struct {
+	build    func() spec.Swagger
+	ttl      time.Duration
+	registry *RouteRegistry
+	blocking bool
+
+	mu         sync.Mutex
+	doc        spec.Swagger
+	built      bool
+	builtAt    time.Time
+	generation uint64
+	refreshing bool
+}
+
+func (c *specCache) get() spec.Swagger {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expired := time.Since(c.builtAt) > c.ttl
+	if generation := c.registry.current(); !c.built || generation != c.generation || (expired && c.blocking) {
+		c.doc, c.builtAt, c.generation, c.built = c.build(), time.Now(), generation, true
+		return c.doc
+	}
+	if expired && !c.refreshing {
+		c.refreshing = true
+		go c.refresh()
+	}
+	return c.doc
+}
+
+func (c *specCache) refresh() {
+	generation := c.registry.current()
+	doc := c.build()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	if generation != c.generation {
+		// Invalidated while building, so the document was already rebuilt.
+		return
+	}
+	c.doc, c.builtAt = doc, time.Now()
+}
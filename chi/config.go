@@ -0,0 +1,238 @@
+package chi
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-openapi/spec"
+)
+
+// Config controls how the Swagger document is generated and served.
+type Config struct {
+	// Info becomes the document's `info` block.
+	Info *spec.Info
+	// ExternalDocs links the document to further documentation.
+	ExternalDocs *spec.ExternalDocumentation
+	// Host and Schemes tell clients where the API is served.
+	Host    string
+	Schemes []string
+	// BasePath becomes the document's `basePath`, and is stripped from the
+	// routes under it.
+	BasePath string
+	// HotReload rebuilds the document on every request instead of once.
+	HotReload bool
+	// CacheTTL, when set, serves the built document for CacheTTL and then
+	// rebuilds it in the background while still serving the stale one. It
+	// takes precedence over HotReload.
+	CacheTTL time.Duration
+	// RouteRegistry invalidates the document cached for CacheTTL as soon as
+	// routes change.
+	RouteRegistry *RouteRegistry
+	// DeduplicateSchemas merges definitions with identical schemas and
+	// rewrites references to point at a single canonical name.
+	DeduplicateSchemas bool
+	// Webhooks documents the outbound requests the service sends.
+	Webhooks []WebhookDoc
+	// MiddlewareDoc lists the middleware function names of each route in the
+	// `x-middlewares` operation extension.
+	MiddlewareDoc bool
+	// SecurityDefinitions become the document's `securityDefinitions`.
+	SecurityDefinitions spec.SecurityDefinitions
+	// DefinitionNamer names the definition of t, and is used for both the
+	// definition key and every `$ref` to it. It defaults to the type name,
+	// which collides for same-named types from different packages.
+	DefinitionNamer func(t reflect.Type) string
+	// YAMLSupport serves the document as YAML to requests asking for it,
+	// see WithYAMLSupport.
+	YAMLSupport bool
+	// StripBasePath moves the path prefix shared by every route, e.g. of a
+	// chi route group, to the document's `basePath`. It has no effect when
+	// BasePath is set.
+	StripBasePath bool
+	// DefaultErrorSchema documents the `default` response of every
+	// operation. It defaults to an object with a string `error` property,
+	// matching the errors written by the middleware package.
+	DefaultErrorSchema *spec.Schema
+}
+
+type SpecOption func(*Config)
+
+func newConfig(opts ...SpecOption) Config {
+	var cfg Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// info returns cfg.Info, creating it first if needed.
+func (cfg *Config) info() *spec.Info {
+	if cfg.Info == nil {
+		cfg.Info = &spec.Info{}
+	}
+	return cfg.Info
+}
+
+func WithTitle(s string) SpecOption {
+	return func(cfg *Config) {
+		cfg.info().Title = s
+	}
+}
+
+func WithVersion(s string) SpecOption {
+	return func(cfg *Config) {
+		cfg.info().Version = s
+	}
+}
+
+func WithDescription(s string) SpecOption {
+	return func(cfg *Config) {
+		cfg.info().Description = s
+	}
+}
+
+func WithLicense(name string, url string) SpecOption {
+	return func(cfg *Config) {
+		cfg.info().License = &spec.License{LicenseProps: spec.LicenseProps{Name: name, URL: url}}
+	}
+}
+
+func WithContact(name string, email string, url string) SpecOption {
+	return func(cfg *Config) {
+		cfg.info().Contact = &spec.ContactInfo{ContactInfoProps: spec.ContactInfoProps{Name: name, Email: email, URL: url}}
+	}
+}
+
+func WithExternalDocs(url string, description string) SpecOption {
+	return func(cfg *Config) {
+		cfg.ExternalDocs = &spec.ExternalDocumentation{URL: url, Description: description}
+	}
+}
+
+func WithHost(s string) SpecOption {
+	return func(cfg *Config) {
+		cfg.Host = s
+	}
+}
+
+func WithBasePath(s string) SpecOption {
+	return func(cfg *Config) {
+		cfg.BasePath = s
+	}
+}
+
+func WithSchemes(schemes ...string) SpecOption {
+	return func(cfg *Config) {
+		cfg.Schemes = schemes
+	}
+}
+
+// WithYAMLSupport serves the document as YAML when the request has
+// `?format=yaml` or accepts `application/x-yaml`. JSON remains the default.
+func WithYAMLSupport() SpecOption {
+	return func(cfg *Config) {
+		cfg.YAMLSupport = true
+	}
+}
+
+func WithHotReload() SpecOption {
+	return func(cfg *Config) {
+		cfg.HotReload = true
+	}
+}
+
+func WithCacheTTL(d time.Duration) SpecOption {
+	return func(cfg *Config) {
+		cfg.CacheTTL = d
+	}
+}
+
+func WithRouteRegistry(registry *RouteRegistry) SpecOption {
+	return func(cfg *Config) {
+		cfg.RouteRegistry = registry
+	}
+}
+
+func WithDeduplicateSchemas() SpecOption {
+	return func(cfg *Config) {
+		cfg.DeduplicateSchemas = true
+	}
+}
+
+func WithWebhookDocs(webhooks []WebhookDoc) SpecOption {
+	return func(cfg *Config) {
+		cfg.Webhooks = append(cfg.Webhooks, webhooks...)
+	}
+}
+
+func WithMiddlewareDoc() SpecOption {
+	return func(cfg *Config) {
+		cfg.MiddlewareDoc = true
+	}
+}
+
+func WithDefinitionNamer(namer func(t reflect.Type) string) SpecOption {
+	return func(cfg *Config) {
+		cfg.DefinitionNamer = namer
+	}
+}
+
+// WithAPIKeySecurity documents an apiKey security scheme called name, sent as
+// paramName in the given location, "header" or "query". Swagger 2.0 cannot
+// document API keys sent as cookies, so WithAPIKeySecurity panics for any
+// other location; use the openapi3 package for those.
+func WithAPIKeySecurity(name string, paramName string, in string) SpecOption {
+	if in != "header" && in != "query" {
+		panic(fmt.Sprintf("chi: API key location %q is not \"header\" or \"query\"", in))
+	}
+	return func(cfg *Config) {
+		if cfg.SecurityDefinitions == nil {
+			cfg.SecurityDefinitions = spec.SecurityDefinitions{}
+		}
+		cfg.SecurityDefinitions[name] = spec.APIKeyAuth(paramName, in)
+	}
+}
+
+func WithStripBasePath(strip bool) SpecOption {
+	return func(cfg *Config) {
+		cfg.StripBasePath = strip
+	}
+}
+
+// WithSecurityDefinition adds def to the document's `securityDefinitions`
+// as name. Handlers reference it by name through ghttp.SecurityRequirer.
+func WithSecurityDefinition(name string, def spec.SecurityScheme) SpecOption {
+	return func(cfg *Config) {
+		if cfg.SecurityDefinitions == nil {
+			cfg.SecurityDefinitions = spec.SecurityDefinitions{}
+		}
+		cfg.SecurityDefinitions[name] = &def
+	}
+}
+
+// WithBearerSecurity documents an HTTP bearer token scheme called name. Swagger
+// 2.0 has no bearer type, so it is described as an apiKey sent in the
+// Authorization header.
+func WithBearerSecurity(name string) SpecOption {
+	scheme := spec.APIKeyAuth("Authorization", "header")
+	scheme.Description = "Bearer token, sent as `Authorization: Bearer <token>`."
+	return WithSecurityDefinition(name, *scheme)
+}
+
+func WithDefaultErrorSchema(schema *spec.Schema) SpecOption {
+	return func(cfg *Config) {
+		cfg.DefaultErrorSchema = schema
+	}
+}
+
+// defaultErrorResponse is the `default` response added to every operation.
+func (cfg Config) defaultErrorResponse() *spec.Response {
+	schema := cfg.DefaultErrorSchema
+	if schema == nil {
+		schema = new(spec.Schema).
+			Typed("object", "").
+			SetProperty("error", *spec.StringProperty())
+	}
+	return spec.NewResponse().WithDescription("Error").WithSchema(schema)
+}
@@ -0,0 +1,77 @@
+package chi
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerInfo describes a route as the Swagger document would, for tools
+// that would rather not parse the document. Fields the handler does not
+// provide are left zero.
+type HandlerInfo struct {
+	Method       string
+	Path         string
+	PayloadType  reflect.Type
+	ResponseType reflect.Type
+	Tags         []string
+	Summary      string
+	OperationID  string
+	IsDeprecated bool
+	SunsetDate   time.Time
+}
+
+// DiscoverHandlers returns a HandlerInfo for every route in r, using the
+// same handler interfaces as the Swagger document.
+func DiscoverHandlers(r chi.Router) []HandlerInfo {
+	var infos []HandlerInfo
+	chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if _, ok := handler.(docsHandler); ok {
+			return nil
+		}
+		info := HandlerInfo{
+			Method:      method,
+			Path:        route,
+			Tags:        handlerTags(route, handler),
+			OperationID: handlerOperationID(method, route, handler),
+		}
+
+		var describer ghttp.Describer
+		describer, _ = handler.(ghttp.Describer)
+		if describer != nil {
+			info.Summary = describer.Summary()
+		}
+
+		var deprecator ghttp.Deprecator
+		deprecator, _ = handler.(ghttp.Deprecator)
+		if deprecator != nil {
+			info.IsDeprecated = deprecator.IsDeprecated()
+		}
+
+		var sunsetter ghttp.Sunsetter
+		sunsetter, _ = handler.(ghttp.Sunsetter)
+		if sunsetter != nil {
+			info.SunsetDate = sunsetter.SunsetDate()
+		}
+
+		var pTyper ghttp.PayloadTyper
+		pTyper, _ = handler.(ghttp.PayloadTyper)
+		if pTyper != nil {
+			info.PayloadType = pTyper.PayloadType()
+		}
+
+		var rTyper ghttp.ResponseTyper
+		rTyper, _ = handler.(ghttp.ResponseTyper)
+		if rTyper != nil {
+			info.ResponseType = rTyper.ResponseType()
+		}
+
+		infos = append(infos, info)
+		return nil
+	})
+	return infos
+}
@@ -0,0 +1,29 @@
+package chi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDiscoverHandlersSunsetDate(t *testing.T) {
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	r := chi.NewRouter()
+	r.Method(http.MethodGet, "/old", ghttp.Deprecated(respond[string](ghttp.WithSunset(sunset))))
+	r.Method(http.MethodGet, "/new", respond[string]())
+
+	got := map[string]HandlerInfo{}
+	for _, info := range DiscoverHandlers(r) {
+		got[info.Path] = info
+	}
+	if old := got["/old"]; !old.IsDeprecated || !old.SunsetDate.Equal(sunset) {
+		t.Errorf("/old: deprecated %t, sunset %v, want deprecated with sunset %v", old.IsDeprecated, old.SunsetDate, sunset)
+	}
+	if sunsetDate := got["/new"].SunsetDate; !sunsetDate.IsZero() {
+		t.Errorf("/new sunset = %v, want none", sunsetDate)
+	}
+}
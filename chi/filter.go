@@ -0,0 +1,78 @@
+package chi
+
+import (
+	"encoding/json"
+
+	"github.com/go-openapi/spec"
+)
+
+// FilterByTag returns a copy of doc with only the operations tagged tag, and
+// only the definitions they reference directly or indirectly. doc itself is
+// left unchanged.
+func FilterByTag(doc spec.Swagger, tag string) spec.Swagger {
+	filtered := copyDoc(doc)
+	if filtered.Paths == nil {
+		return filtered
+	}
+	paths := map[string]spec.PathItem{}
+	for route, item := range filtered.Paths.Paths {
+		for _, op := range operationSlots(&item) {
+			if *op != nil && !hasTag(*op, tag) {
+				*op = nil
+			}
+		}
+		if len(operations(item)) > 0 {
+			paths[route] = item
+		}
+	}
+	filtered.Paths.Paths = paths
+
+	reachable := map[string]bool{}
+	var visit func(*spec.Schema)
+	visit = func(s *spec.Schema) {
+		name := refName(s)
+		if name == "" || reachable[name] {
+			return
+		}
+		reachable[name] = true
+		if def, ok := filtered.Definitions[name]; ok {
+			walkSchema(&def, visit)
+		}
+	}
+	for _, item := range paths {
+		for _, op := range operations(item) {
+			walkOperationSchemas(op, visit)
+		}
+	}
+	definitions := spec.Definitions{}
+	for name := range reachable {
+		if def, ok := filtered.Definitions[name]; ok {
+			definitions[name] = def
+		}
+	}
+	filtered.Definitions = definitions
+	return filtered
+}
+
+func hasTag(op *spec.Operation, tag string) bool {
+	for _, t := range op.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// copyDoc returns a deep copy of doc, so it can be changed without affecting
+// a document that may be served concurrently.
+func copyDoc(doc spec.Swagger) spec.Swagger {
+	var c spec.Swagger
+	b, err := json.Marshal(doc)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return doc
+	}
+	return c
+}
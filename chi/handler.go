@@ -2,15 +2,19 @@ package chi
 
 import (
 	"encoding/json"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"reflect"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"ghttp"
+	"ghttp/internal/typeschema"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-openapi/spec"
@@ -18,25 +22,137 @@ import (
 
 var (
 	pathParamPattern = regexp.MustCompile("{([^}]+)}")
+	enumerType       = reflect.TypeOf((*ghttp.Enumer)(nil)).Elem()
+	emptyStructType  = reflect.TypeOf(struct{}{})
 )
 
+// RegisterInterfaceSchema documents values of interface type T with schema
+// instead of as an untyped value. It is not safe for concurrent use and
+// should be called during initialization.
+func RegisterInterfaceSchema[T any](schema *spec.Schema) {
+	typeschema.InterfaceSchemas[reflect.TypeOf((*T)(nil)).Elem()] = *schema
+}
+
+// RegisterTypeFormatter documents values of the type named typeName, as
+// printed by reflect.Type.String (e.g. "decimal.Decimal"), with the schema fn
+// returns. The schema gets an `x-go-type` extension naming the Go type.
+// Pointers to the type are documented as nullable. It is not safe for
+// concurrent use and should be called during initialization.
+func RegisterTypeFormatter(typeName string, fn func() *spec.Schema) {
+	typeschema.TypeFormatters[typeName] = fn
+}
+
+// MiddlewareParamDocumenter is implemented by the http.Handler a middleware
+// returns when it wants to document the parameters it reads, e.g. an auth
+// header.
+type MiddlewareParamDocumenter interface {
+	MiddlewareParams() []spec.Parameter
+}
+
+// MiddlewareNamer is implemented by the http.Handler a middleware returns when
+// it should be listed under a name of its own in `x-middlewares`.
+type MiddlewareNamer interface {
+	MiddlewareName() string
+}
+
+// SpecOverrider is implemented by handlers whose operation cannot be inferred
+// by reflection, e.g. because of polymorphic schemas. The operation it returns
+// is documented verbatim. Definitions its `$ref`s point to are only added for
+// the handler's PayloadType and ResponseType, if it has them.
+type SpecOverrider interface {
+	SwaggerOperation() *spec.Operation
+}
+
 func HandlerFunc(r chi.Router) http.HandlerFunc {
-	onceFn := sync.OnceValue(func() spec.Swagger {
-		return initializeDoc(r)
-	})
+	return HandlerFuncWithOptions(r)
+}
+
+func HandlerFuncWithOptions(r chi.Router, opts ...SpecOption) http.HandlerFunc {
+	return handlerFunc(r, newConfig(opts...))
+}
+
+// HandlerFuncDev rebuilds the document on every request, so routes added
+// while developing show up without a restart.
+func HandlerFuncDev(r chi.Router) http.HandlerFunc {
+	return HandlerFuncWithOptions(r, WithHotReload())
+}
+
+// HandlerFuncWithTTL rebuilds the document on the first request after ttl
+// has elapsed since it was last built.
+func HandlerFuncWithTTL(r chi.Router, ttl time.Duration) http.HandlerFunc {
+	cache := &specCache{
+		build: func() spec.Swagger {
+			return initializeDoc(r, Config{})
+		},
+		ttl:      ttl,
+		blocking: true,
+	}
+	return jsonHandlerFunc(cache.get)
+}
+
+// Swagger generates the Swagger document for r.
+func Swagger(r chi.Router, cfg Config) spec.Swagger {
+	return initializeDoc(r, cfg)
+}
+
+func handlerFunc(r chi.Router, cfg Config) http.HandlerFunc {
+	docFn := docFunc(r, cfg)
+	if cfg.YAMLSupport {
+		return negotiatedHandlerFunc(docFn)
+	}
+	return jsonHandlerFunc(docFn)
+}
+
+// negotiatedHandlerFunc serves the document as YAML when the request asks for
+// it with `?format=yaml` or an `Accept: application/x-yaml` header, and as
+// JSON otherwise.
+func negotiatedHandlerFunc(docFn func() spec.Swagger) http.HandlerFunc {
+	jsonFn, yamlFn := jsonHandlerFunc(docFn), yamlHandlerFunc(docFn)
+	return func(w http.ResponseWriter, req *http.Request) {
+		accept := req.Header.Get("Accept")
+		if req.URL.Query().Get("format") == "yaml" || strings.Contains(accept, "application/x-yaml") || strings.Contains(accept, "application/yaml") {
+			yamlFn(w, req)
+			return
+		}
+		jsonFn(w, req)
+	}
+}
+
+// docFunc returns a function building the document for r, once unless
+// cfg.CacheTTL or cfg.HotReload is set.
+func docFunc(r chi.Router, cfg Config) func() spec.Swagger {
+	docFn := func() spec.Swagger {
+		return initializeDoc(r, cfg)
+	}
+	if cfg.CacheTTL > 0 {
+		cache := &specCache{build: docFn, ttl: cfg.CacheTTL, registry: cfg.RouteRegistry}
+		return cache.get
+	}
+	if !cfg.HotReload {
+		docFn = sync.OnceValue(docFn)
+	}
+	return docFn
+}
+
+// jsonHandlerFunc serves the document as JSON, filtered with FilterByTag when
+// the request has a `tag` query parameter.
+func jsonHandlerFunc(docFn func() spec.Swagger) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		doc := onceFn()
+		doc := docFn()
+		if tag := req.URL.Query().Get("tag"); tag != "" {
+			doc = FilterByTag(doc, tag)
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		enc := json.NewEncoder(w)
 		if err := enc.Encode(doc); err != nil {
-			fmt.Printf("Error encoding doc: %s\n", err.Error())
+			ghttp.Logger().Error("encoding doc", slog.Any("error", err))
 			return
 		}
 	}
 }
 
-func initializeDoc(r chi.Router) spec.Swagger {
+func initializeDoc(r chi.Router, cfg Config) spec.Swagger {
 	doc := spec.Swagger{
 		SwaggerProps: spec.SwaggerProps{
 			Swagger:     "2.0",
@@ -44,161 +160,432 @@ func initializeDoc(r chi.Router) spec.Swagger {
 			Paths: &spec.Paths{
 				Paths: map[string]spec.PathItem{},
 			},
+			Info:                cfg.Info,
+			ExternalDocs:        cfg.ExternalDocs,
+			Host:                cfg.Host,
+			Schemes:             cfg.Schemes,
+			SecurityDefinitions: cfg.SecurityDefinitions,
 		},
 	}
+	basePath := docBasePath(r, cfg)
+	doc.BasePath = basePath
 	chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if _, ok := handler.(docsHandler); ok {
+			return nil
+		}
+		// Tags and operation IDs come from the route relative to basePath too.
+		route = relativeRoute(route, basePath)
 		if _, ok := doc.Paths.Paths[route]; !ok {
 			doc.SwaggerProps.Paths.Paths[route] = spec.PathItem{}
 		}
-		operation := spec.NewOperation("")
+
+		var overrider SpecOverrider
+		overrider, _ = handler.(SpecOverrider)
+		if overrider != nil && overrider.SwaggerOperation() != nil {
+			addOverriddenDefinitions(doc, cfg, handler)
+			setOperation(doc, route, method, overrider.SwaggerOperation())
+			return nil
+		}
+
+		operation := spec.NewOperation(handlerOperationID(method, route, handler))
+		operation.WithTags(handlerTags(route, handler)...)
+
+		var describer ghttp.Describer
+		describer, _ = handler.(ghttp.Describer)
+		if describer != nil {
+			operation.WithSummary(describer.Summary())
+			operation.WithDescription(describer.Description())
+		}
+
+		var deprecator ghttp.Deprecator
+		deprecator, _ = handler.(ghttp.Deprecator)
+		if deprecator != nil && deprecator.IsDeprecated() {
+			operation.Deprecate()
+		}
+
+		var sunsetter ghttp.Sunsetter
+		sunsetter, _ = handler.(ghttp.Sunsetter)
+		if sunsetter != nil && !sunsetter.SunsetDate().IsZero() {
+			operation.AddExtension("x-sunset", sunsetter.SunsetDate().UTC().Format(time.RFC3339))
+		}
+
+		var edLinker ghttp.ExternalDocsLinker
+		edLinker, _ = handler.(ghttp.ExternalDocsLinker)
+		if edLinker != nil {
+			if url, description := edLinker.ExternalDocs(); url != "" {
+				operation.ExternalDocs = &spec.ExternalDocumentation{URL: url, Description: description}
+			}
+		}
 
 		var pTyper ghttp.PayloadTyper
 		pTyper, _ = handler.(ghttp.PayloadTyper)
-		if pTyper != nil {
+		var consumer ghttp.Consumer
+		consumer, _ = handler.(ghttp.Consumer)
+		if consumer != nil {
+			operation.Consumes = consumer.Consumes()
+		}
+		if pTyper != nil && slices.Contains(operation.Consumes, "application/x-www-form-urlencoded") {
+			for _, parameter := range structParams(doc, cfg, pTyper.PayloadType(), spec.FormDataParam, "form") {
+				operation.AddParam(parameter)
+			}
+		} else if pTyper != nil {
 			pt := pTyper.PayloadType()
-			addDefinition(doc, pt)
-			parameter := spec.BodyParam(getName(pt), spec.RefProperty("#/definitions/"+getName(pt)))
+			name := getName(cfg, pt)
+			if name == "" {
+				name = "body"
+			}
+			parameter := spec.BodyParam(name, typeRef(doc, cfg, pt))
 			operation.AddParam(parameter)
 		}
 
+		var producer ghttp.Producer
+		producer, _ = handler.(ghttp.Producer)
+		if producer != nil {
+			operation.Produces = producer.Produces()
+			if slices.Contains(operation.Produces, "text/event-stream") {
+				// Swagger 2.0 cannot describe a stream, so the response schema
+				// documents a single event's data.
+				operation.AddExtension("x-streaming", true)
+			}
+		}
+
+		var mrTyper ghttp.MultiResponseTyper
+		mrTyper, _ = handler.(ghttp.MultiResponseTyper)
 		var rTyper ghttp.ResponseTyper
 		rTyper, _ = handler.(ghttp.ResponseTyper)
-		if rTyper != nil {
+		if mrTyper != nil {
+			for statusCode, rt := range mrTyper.ResponseTypes() {
+				resp := spec.NewResponse()
+				if rt != emptyStructType {
+					resp.Schema = typeRef(doc, cfg, rt)
+				}
+				operation.RespondsWith(statusCode, resp)
+			}
+		} else if rTyper != nil {
 			rt := rTyper.ResponseType()
-			addDefinition(doc, rt)
-			resp := spec.NewResponse()
-			resp.Schema = spec.RefProperty("#/definitions/" + getName(rt))
-			operation.RespondsWith(http.StatusOK, resp)
-		}
-
-		//var hAdder ghttp.HeaderAdder
-		//hAdder, _ = handler.(ghttp.HeaderAdder)
-		//if hAdder != nil {
-		//	headers := hAdder.HeaderAdd()
-		//	for _, header := range headers {
-		//		parameter := spec.HeaderParam(header)
-		//		operation.AddParam(parameter)
-		//	}
-		//}
-		// TODO: Add Header through Middleware?
+			if rt == emptyStructType {
+				operation.RespondsWith(http.StatusNoContent, spec.NewResponse())
+			} else {
+				resp := spec.NewResponse()
+				resp.Schema = typeRef(doc, cfg, rt)
+				operation.RespondsWith(http.StatusOK, resp)
+			}
+		}
+		operation.RespondsWith(0, cfg.defaultErrorResponse())
+
+		var rhTyper ghttp.ResponseHeaderTyper
+		rhTyper, _ = handler.(ghttp.ResponseHeaderTyper)
+		if rhTyper != nil {
+			addResponseHeaders(doc, cfg, operation, rhTyper.ResponseHeadersForStatus())
+		}
+
+		var hmacWebhook ghttp.HMACWebhook
+		hmacWebhook, _ = handler.(ghttp.HMACWebhook)
+		if hmacWebhook != nil && hmacWebhook.WebhookSignatureHeader() != "" {
+			operation.AddExtension("x-webhook-signature", map[string]string{
+				"header":    hmacWebhook.WebhookSignatureHeader(),
+				"algorithm": "HMAC-SHA256",
+			})
+		}
+
+		var wsDocumenter ghttp.WSDocumenter
+		wsDocumenter, _ = handler.(ghttp.WSDocumenter)
+		if wsDocumenter != nil {
+			in, out := wsDocumenter.WSMessageType()
+			messages := map[string]*spec.Schema{}
+			if in != nil {
+				messages["inMessage"] = typeRef(doc, cfg, in)
+			}
+			if out != nil {
+				messages["outMessage"] = typeRef(doc, cfg, out)
+			}
+			operation.AddExtension("x-websocket", messages)
+		}
+
+		var tProvider ghttp.TimeoutProvider
+		tProvider, _ = handler.(ghttp.TimeoutProvider)
+		if tProvider != nil && tProvider.HandlerTimeout() > 0 {
+			operation.AddExtension("x-timeout-ms", int(tProvider.HandlerTimeout().Milliseconds()))
+		}
+
+		if hint, ok := sdkHint(handler); ok {
+			operation.AddExtension("x-client-sdk", hint)
+		}
+
+		var sRequirer ghttp.SecurityRequirer
+		sRequirer, _ = handler.(ghttp.SecurityRequirer)
+		if sRequirer != nil {
+			operation.Security = append(operation.Security, sRequirer.SecurityRequirements()...)
+		}
+
+		var hAdder ghttp.HeaderAdder
+		hAdder, _ = handler.(ghttp.HeaderAdder)
+		if hAdder != nil {
+			headers := hAdder.HeaderAdd()
+			for _, header := range headers {
+				parameter := spec.HeaderParam(header)
+				operation.AddParam(parameter)
+			}
+		}
+
+		for _, mw := range middlewares {
+			var mpDocumenter MiddlewareParamDocumenter
+			mpDocumenter, _ = mw(http.NotFoundHandler()).(MiddlewareParamDocumenter)
+			if mpDocumenter != nil {
+				for _, parameter := range mpDocumenter.MiddlewareParams() {
+					operation.AddParam(&parameter)
+				}
+			}
+		}
+		if cfg.MiddlewareDoc && len(middlewares) > 0 {
+			operation.AddExtension("x-middlewares", middlewareNames(middlewares))
+		}
+
+		var qpTyper ghttp.QueryParamTyper
+		qpTyper, _ = handler.(ghttp.QueryParamTyper)
+		if qpTyper != nil && qpTyper.QueryParamType() != nil {
+			for _, parameter := range queryParams(doc, cfg, qpTyper.QueryParamType()) {
+				operation.AddParam(parameter)
+			}
+		}
+
+		typedPathParams := map[string]*spec.Parameter{}
+		var ppTyper ghttp.PathParamTyper
+		ppTyper, _ = handler.(ghttp.PathParamTyper)
+		if ppTyper != nil && ppTyper.PathParamType() != nil {
+			for _, parameter := range structParams(doc, cfg, ppTyper.PathParamType(), spec.PathParam, ghttp.PathParamTags...) {
+				typedPathParams[parameter.Name] = parameter
+			}
+		}
 
 		pathParams := pathParamPattern.FindAllStringSubmatch(route, -1)
 		for _, pathParam := range pathParams {
-			parameter := spec.PathParam(pathParam[1])
+			parameter, ok := typedPathParams[pathParam[1]]
+			if !ok {
+				parameter = spec.PathParam(pathParam[1])
+			}
 			operation.AddParam(parameter)
 		}
 
-		pathItem := doc.SwaggerProps.Paths.Paths[route]
-		switch method {
-		case http.MethodGet:
-			pathItem.PathItemProps.Get = operation
-		case http.MethodPut:
-			pathItem.PathItemProps.Put = operation
-		case http.MethodPost:
-			pathItem.PathItemProps.Post = operation
-		case http.MethodDelete:
-			pathItem.PathItemProps.Delete = operation
-		case http.MethodOptions:
-			pathItem.PathItemProps.Options = operation
-		case http.MethodHead:
-			pathItem.PathItemProps.Head = operation
-		case http.MethodPatch:
-			pathItem.PathItemProps.Patch = operation
-		}
-		doc.SwaggerProps.Paths.Paths[route] = pathItem
+		setOperation(doc, route, method, operation)
 		return nil
 	})
+	addWebhooks(&doc, cfg)
+	if cfg.DeduplicateSchemas {
+		dedupeDefinitions(doc)
+	}
 	return doc
 }
 
-func addDefinition(doc spec.Swagger, t reflect.Type) {
-	if _, ok := doc.Definitions[getName(t)]; !ok {
-		prop := getProperty(t)
-		if prop != nil {
-			doc.Definitions[getName(t)] = *prop
-		}
-	}
-}
-
-func getName(t reflect.Type) string {
-	switch t.Kind() {
-	case reflect.Pointer:
-		return "*" + getName(t.Elem())
-	default:
-		return strings.ReplaceAll(t.Name(), "/", ".")
-	}
-}
-
-func getProperty(t reflect.Type) *spec.Schema {
-	switch t.String() {
-	case "uuid.UUID":
-		return spec.StrFmtProperty("uuid")
-	case "date.DateString":
-		return spec.DateProperty()
-	}
-	switch t.Kind() {
-	//case reflect.Invalid:
-	case reflect.Bool:
-		return spec.BooleanProperty()
-	case reflect.Int:
-		return spec.Int64Property()
-	case reflect.Int8:
-		return spec.Int8Property()
-	case reflect.Int16:
-		return spec.Int16Property()
-	case reflect.Int32:
-		return spec.Int32Property()
-	case reflect.Int64:
-		return spec.Int64Property()
-	case reflect.Uint:
-		return spec.Int64Property()
-	case reflect.Uint8:
-		return spec.Int8Property()
-	case reflect.Uint16:
-		return spec.Int16Property()
-	case reflect.Uint32:
-		return spec.Int32Property()
-	case reflect.Uint64:
-		return spec.Int64Property()
-	//case reflect.Uintptr:
-	case reflect.Float32:
-		return spec.Float32Property()
-	case reflect.Float64:
-		return spec.Float64Property()
-	//case reflect.Complex64:
-	//case reflect.Complex128:
-	case reflect.Array:
-		return spec.ArrayProperty(getProperty(t.Elem()))
-	//case reflect.Chan:
-	//case reflect.Func:
-	//case reflect.Interface:
-	//case reflect.Map:
-	//	return spec.MapProperty()
-	case reflect.Pointer:
-		property := getProperty(t.Elem())
-		property.Nullable = true
-		return property
-	case reflect.Slice:
-		return spec.ArrayProperty(getProperty(t.Elem()))
-	case reflect.String:
-		return spec.StringProperty()
-	case reflect.Struct:
-		schema := spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Properties: spec.SchemaProperties{},
-			},
+func middlewareNames(middlewares []func(http.Handler) http.Handler) []string {
+	names := make([]string, 0, len(middlewares))
+	for _, mw := range middlewares {
+		var namer MiddlewareNamer
+		namer, _ = mw(http.NotFoundHandler()).(MiddlewareNamer)
+		if namer != nil {
+			names = append(names, namer.MiddlewareName())
+			continue
+		}
+		name := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()).Name()
+		names = append(names, name[strings.LastIndex(name, "/")+1:])
+	}
+	return names
+}
+
+// queryParams documents each field of the struct t as a query parameter, typed
+// from its schema so e.g. time.Time fields get the date-time format.
+func queryParams(doc spec.Swagger, cfg Config, t reflect.Type) []*spec.Parameter {
+	return structParams(doc, cfg, t, spec.QueryParam, "query")
+}
+
+// structParams documents each field of the struct t as a parameter named by
+// the first of the given struct tags it has. A pointer to a struct documents
+// the struct; any other type documents no parameters.
+func structParams(doc spec.Swagger, cfg Config, t reflect.Type, newParam func(string) *spec.Parameter, tags ...string) []*spec.Parameter {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		ghttp.Logger().Warn("Parameter type is not a struct", slog.String("type_name", t.String()))
+		return nil
+	}
+	var parameters []*spec.Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := ghttp.ParamName(f, tags...)
+		if name == "" {
+			continue
+		}
+		parameter := newParam(name)
+		property := getProperty(doc, cfg, f.Type)
+		if property != nil && len(property.Type) > 0 {
+			parameter.Typed(property.Type[0], property.Format)
+		}
+		if enum := paramEnum(f); len(enum) > 0 {
+			parameter.WithEnum(enum...)
+		} else if property != nil && len(property.Enum) > 0 {
+			parameter.WithEnum(property.Enum...)
+		}
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
+
+// paramEnum returns the allowed values of the parameter field f, either from
+// its type implementing ghttp.Enumer or from a `validate:"oneof=a b c"` tag.
+func paramEnum(f reflect.StructField) []interface{} {
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Call Enum through a pointer to a zero value, never a nil pointer, so
+	// both value and pointer receivers work.
+	if reflect.PointerTo(t).Implements(enumerType) {
+		return reflect.New(t).Interface().(ghttp.Enumer).Enum()
+	}
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		values, ok := strings.CutPrefix(rule, "oneof=")
+		if !ok {
+			continue
+		}
+		var enum []interface{}
+		for _, value := range strings.Fields(values) {
+			enum = append(enum, value)
+		}
+		return enum
+	}
+	return nil
+}
+
+// addResponseHeaders documents headers on the response for each status code,
+// adding a response without a schema when there is none yet.
+func addResponseHeaders(doc spec.Swagger, cfg Config, operation *spec.Operation, headers map[int]map[string]reflect.Type) {
+	for statusCode, statusHeaders := range headers {
+		if operation.Responses == nil {
+			operation.RespondsWith(statusCode, spec.NewResponse())
 		}
-		for i := 0; i < t.NumField(); i++ {
-			f := t.Field(i)
-			property := getProperty(f.Type)
-			if property != nil {
-				schema.SchemaProps.Properties[strings.Split(f.Tag.Get("json"), ",")[0]] = *property
+		resp, ok := operation.Responses.StatusCodeResponses[statusCode]
+		if !ok {
+			resp = *spec.NewResponse()
+		}
+		for name, t := range statusHeaders {
+			header := spec.ResponseHeader()
+			if property := getProperty(doc, cfg, t); property != nil && len(property.Type) > 0 {
+				header.Typed(property.Type[0], property.Format)
 			}
+			resp.AddHeader(name, header)
 		}
-		return &schema
-	//case reflect.UnsafePointer:
-	default:
-		log.Printf("Unknown kind for swagger property: %s %s\n", getName(t), t.Kind())
-		return nil
+		operation.RespondsWith(statusCode, &resp)
+	}
+}
+
+// addOverriddenDefinitions adds the definitions of the payload and response
+// types of a SpecOverrider handler, so its operation can reference them.
+func addOverriddenDefinitions(doc spec.Swagger, cfg Config, handler http.Handler) {
+	var pTyper ghttp.PayloadTyper
+	pTyper, _ = handler.(ghttp.PayloadTyper)
+	if pTyper != nil {
+		typeRef(doc, cfg, pTyper.PayloadType())
+	}
+	var rTyper ghttp.ResponseTyper
+	rTyper, _ = handler.(ghttp.ResponseTyper)
+	if rTyper != nil && rTyper.ResponseType() != emptyStructType {
+		typeRef(doc, cfg, rTyper.ResponseType())
+	}
+}
+
+// handlerOperationID returns the operation ID of handler, generated from the
+// method and route unless it implements ghttp.OperationIDer.
+func handlerOperationID(method string, route string, handler http.Handler) string {
+	var opIDer ghttp.OperationIDer
+	opIDer, _ = handler.(ghttp.OperationIDer)
+	if opIDer != nil && opIDer.OperationID() != "" {
+		return opIDer.OperationID()
+	}
+	return operationID(method, route)
+}
+
+// handlerTags returns the tags of handler, defaulting to the first literal
+// segment of route, e.g. "users" for "/users/{id}".
+func handlerTags(route string, handler http.Handler) []string {
+	var tagger ghttp.Tagger
+	tagger, _ = handler.(ghttp.Tagger)
+	if tagger != nil && len(tagger.Tags()) > 0 {
+		return tagger.Tags()
+	}
+	for _, segment := range strings.Split(route, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			return []string{segment}
+		}
+	}
+	return nil
+}
+
+// operationID joins the method and the words of route in camelCase, e.g.
+// "GET /users/{id}" becomes "getUsersId". Path parameter patterns such as
+// "{id:[0-9]+}" are left out.
+func operationID(method string, route string) string {
+	route = pathParamPattern.ReplaceAllStringFunc(route, func(param string) string {
+		name, _, _ := strings.Cut(param, ":")
+		return name
+	})
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	words := strings.FieldsFunc(route, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	return b.String()
+}
+
+func setOperation(doc spec.Swagger, route string, method string, operation *spec.Operation) {
+	pathItem := doc.SwaggerProps.Paths.Paths[route]
+	switch method {
+	case http.MethodGet:
+		pathItem.PathItemProps.Get = operation
+	case http.MethodPut:
+		pathItem.PathItemProps.Put = operation
+	case http.MethodPost:
+		pathItem.PathItemProps.Post = operation
+	case http.MethodDelete:
+		pathItem.PathItemProps.Delete = operation
+	case http.MethodOptions:
+		pathItem.PathItemProps.Options = operation
+	case http.MethodHead:
+		pathItem.PathItemProps.Head = operation
+	case http.MethodPatch:
+		pathItem.PathItemProps.Patch = operation
+	}
+	doc.SwaggerProps.Paths.Paths[route] = pathItem
+}
+
+// generator returns the schema generator adding definitions to doc.
+func generator(doc spec.Swagger, cfg Config) typeschema.Generator {
+	return typeschema.Generator{
+		Definitions:       doc.Definitions,
+		RefPrefix:         definitionsPrefix,
+		DefinitionNamer:   cfg.DefinitionNamer,
+		NullableExtension: true,
 	}
 }
+
+// typeRef adds the definition of t and returns a schema referencing it.
+func typeRef(doc spec.Swagger, cfg Config, t reflect.Type) *spec.Schema {
+	return generator(doc, cfg).TypeRef(t)
+}
+
+func addDefinition(doc spec.Swagger, cfg Config, t reflect.Type) {
+	generator(doc, cfg).AddDefinition(t)
+}
+
+func getName(cfg Config, t reflect.Type) string {
+	return generator(spec.Swagger{}, cfg).Name(t)
+}
+
+func getProperty(doc spec.Swagger, cfg Config, t reflect.Type) *spec.Schema {
+	return generator(doc, cfg).Property(t)
+}
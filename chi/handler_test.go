@@ -0,0 +1,775 @@
+package chi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ghttp"
+	"ghttp/internal/typeschema"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-openapi/spec"
+	"gopkg.in/yaml.v3"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+// testItemCopy is identical to testItem, so deduplication merges the two.
+type testItemCopy struct {
+	Name string `json:"name"`
+}
+
+type testMaps struct {
+	Counts map[string]int               `json:"counts"`
+	Items  map[string]testItem          `json:"items"`
+	Labels map[string]map[string]string `json:"labels"`
+}
+
+type testNode struct {
+	Value int       `json:"value"`
+	Left  *testNode `json:"left"`
+	Right *testNode `json:"right"`
+}
+
+type testTimes struct {
+	Created time.Time  `json:"created"`
+	Deleted *time.Time `json:"deleted"`
+}
+
+type testBytes struct {
+	Data []byte `json:"data"`
+}
+
+type testURLs struct {
+	Homepage url.URL  `json:"homepage"`
+	Avatar   *url.URL `json:"avatar"`
+}
+
+type testStatus string
+
+func (testStatus) EnumValues() []interface{} {
+	return []interface{}{"active", "disabled"}
+}
+
+type testAccount struct {
+	Status testStatus `json:"status"`
+}
+
+type testCreditCard struct {
+	Number string `json:"number"`
+}
+
+type testBankTransfer struct {
+	IBAN string `json:"iban"`
+}
+
+type testPaymentMethod struct{}
+
+func (testPaymentMethod) UnionTypes() ([]reflect.Type, bool) {
+	return []reflect.Type{reflect.TypeOf(testCreditCard{}), reflect.TypeOf(testBankTransfer{})}, true
+}
+
+type testArrays struct {
+	Tags  []string `json:"tags" openapi:"minItems=1"`
+	IDs   []int    `json:"ids" openapi:"maxItems=100"`
+	Codes []string `json:"codes" openapi:"uniqueItems=true"`
+	All   []string `json:"all" openapi:"minItems=1,maxItems=10,uniqueItems=true"`
+}
+
+type testUserParams struct {
+	ID     int64  `path:"id"`
+	Name   string `chi:"name"`
+	Active bool
+}
+
+type namedHandler struct {
+	ghttp.JSONHandler[string]
+}
+
+func (namedHandler) OperationID() string {
+	return "fetchThing"
+}
+
+type overriddenHandler struct {
+	ghttp.JSONPayloadHandler[testItem, testItem]
+}
+
+func (overriddenHandler) SwaggerOperation() *spec.Operation {
+	op := spec.NewOperation("customOp")
+	op.RespondsWith(http.StatusOK, spec.NewResponse().WithSchema(spec.RefProperty("#/definitions/testItem")))
+	return op
+}
+
+type linkedHandler struct {
+	ghttp.JSONHandler[string]
+}
+
+func (linkedHandler) ExternalDocs() (string, string) {
+	return "https://example.com/things", "Things"
+}
+
+// respond returns a JSONHandler responding with the zero O.
+func respond[O any](opts ...ghttp.HandlerOption) ghttp.JSONHandler[O] {
+	return ghttp.NewJSONHandler(func(w http.ResponseWriter, r *http.Request) (O, http.Header, int) {
+		var v O
+		return v, nil, http.StatusOK
+	}, opts...)
+}
+
+func definition(t *testing.T, doc spec.Swagger, name string) spec.Schema {
+	t.Helper()
+	def, ok := doc.Definitions[name]
+	if !ok {
+		t.Fatalf("definition %s missing, have %v", name, definitionNames(doc))
+	}
+	return def
+}
+
+func definitionNames(doc spec.Swagger) []string {
+	var names []string
+	for name := range doc.Definitions {
+		names = append(names, name)
+	}
+	return names
+}
+
+func operation(t *testing.T, doc spec.Swagger, method string, route string) *spec.Operation {
+	t.Helper()
+	item, ok := doc.Paths.Paths[route]
+	if !ok {
+		t.Fatalf("path %s missing", route)
+	}
+	op := *operationSlots(&item)[method]
+	if op == nil {
+		t.Fatalf("%s %s missing", method, route)
+	}
+	return op
+}
+
+func marshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestSwagger(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes func(r chi.Router)
+		opts   []SpecOption
+		check  func(t *testing.T, doc spec.Swagger)
+	}{
+		{
+			name: "maps",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/maps", respond[testMaps]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				def := definition(t, doc, "testMaps")
+				if got := def.Properties["counts"].AdditionalProperties.Schema.Type; !got.Contains("integer") {
+					t.Errorf("counts values typed %v, want integer", got)
+				}
+				if got := def.Properties["items"].AdditionalProperties.Schema.Ref.String(); got != "#/definitions/testItem" {
+					t.Errorf("items values reference %q, want #/definitions/testItem", got)
+				}
+				inner := def.Properties["labels"].AdditionalProperties.Schema
+				if !inner.Type.Contains("object") || !inner.AdditionalProperties.Schema.Type.Contains("string") {
+					t.Errorf("labels values = %s, want a map of strings", marshal(t, inner))
+				}
+				definition(t, doc, "testItem")
+			},
+		},
+		{
+			name: "self-referential struct",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/tree", respond[testNode]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				def := definition(t, doc, "testNode")
+				for _, field := range []string{"left", "right"} {
+					property := def.Properties[field]
+					if len(property.AllOf) != 1 || property.AllOf[0].Ref.String() != "#/definitions/testNode" || !nullable(property) {
+						t.Errorf("%s = %s, want a nullable reference to testNode", field, marshal(t, property))
+					}
+				}
+				marshal(t, doc)
+			},
+		},
+		{
+			name: "time",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/times", respond[testTimes]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				def := definition(t, doc, "testTimes")
+				created, deleted := def.Properties["created"], def.Properties["deleted"]
+				if !created.Type.Contains("string") || created.Format != "date-time" || nullable(created) {
+					t.Errorf("created = %s, want a date-time string", marshal(t, created))
+				}
+				if !deleted.Type.Contains("string") || deleted.Format != "date-time" || !nullable(deleted) {
+					t.Errorf("deleted = %s, want a nullable date-time string", marshal(t, deleted))
+				}
+			},
+		},
+		{
+			name: "byte slice",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/bytes", respond[testBytes]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				data := definition(t, doc, "testBytes").Properties["data"]
+				if !data.Type.Contains("string") || data.Format != "byte" {
+					t.Errorf("data = %s, want a byte string", marshal(t, data))
+				}
+			},
+		},
+		{
+			name: "url",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/urls", respond[testURLs]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				def := definition(t, doc, "testURLs")
+				homepage, avatar := def.Properties["homepage"], def.Properties["avatar"]
+				if !homepage.Type.Contains("string") || homepage.Format != "uri" || nullable(homepage) {
+					t.Errorf("homepage = %s, want a uri string", marshal(t, homepage))
+				}
+				if !avatar.Type.Contains("string") || avatar.Format != "uri" || !nullable(avatar) {
+					t.Errorf("avatar = %s, want a nullable uri string", marshal(t, avatar))
+				}
+			},
+		},
+		{
+			name: "enum values",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/accounts", respond[testAccount]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				status := definition(t, doc, "testAccount").Properties["status"]
+				if want := []interface{}{"active", "disabled"}; !reflect.DeepEqual(status.Enum, want) {
+					t.Errorf("status enum = %v, want %v", status.Enum, want)
+				}
+			},
+		},
+		{
+			name: "operation ids",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/users/{id}", respond[string]())
+				r.Method(http.MethodPost, "/orders", respond[string]())
+				r.Method(http.MethodGet, "/items/{id:[0-9]+}/parts", respond[string]())
+				r.Method(http.MethodGet, "/things", namedHandler{respond[string]()})
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				for _, tt := range []struct {
+					method, route, want string
+				}{
+					{http.MethodGet, "/users/{id}", "getUsersId"},
+					{http.MethodPost, "/orders", "postOrders"},
+					{http.MethodGet, "/items/{id:[0-9]+}/parts", "getItemsIdParts"},
+					{http.MethodGet, "/things", "fetchThing"},
+				} {
+					if got := operation(t, doc, tt.method, tt.route).ID; got != tt.want {
+						t.Errorf("%s %s operationId = %q, want %q", tt.method, tt.route, got, tt.want)
+					}
+				}
+			},
+		},
+		{
+			name: "info",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/users", respond[string]())
+			},
+			opts: []SpecOption{
+				WithTitle("Users"),
+				WithVersion("1.2.0"),
+				WithDescription("Manages users."),
+				WithLicense("MIT", "https://opensource.org/licenses/MIT"),
+				WithContact("API team", "api@example.com", "https://example.com"),
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				info := doc.Info
+				if info == nil || info.Title != "Users" || info.Version != "1.2.0" || info.Description != "Manages users." {
+					t.Fatalf("info = %s", marshal(t, info))
+				}
+				if info.License == nil || info.License.Name != "MIT" {
+					t.Errorf("license = %s, want MIT", marshal(t, info.License))
+				}
+				if info.Contact == nil || info.Contact.Email != "api@example.com" {
+					t.Errorf("contact = %s, want api@example.com", marshal(t, info.Contact))
+				}
+			},
+		},
+		{
+			name: "host, base path and schemes",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/api/users", respond[string]())
+			},
+			opts: []SpecOption{WithHost("api.example.com"), WithBasePath("/api"), WithSchemes("https")},
+			check: func(t *testing.T, doc spec.Swagger) {
+				if doc.Host != "api.example.com" || doc.BasePath != "/api" || !reflect.DeepEqual(doc.Schemes, []string{"https"}) {
+					t.Errorf("host, basePath, schemes = %q, %q, %v", doc.Host, doc.BasePath, doc.Schemes)
+				}
+				if _, ok := doc.Paths.Paths["/api/users"]; ok {
+					t.Error("/api/users not stripped of the base path")
+				}
+				if got := operation(t, doc, http.MethodGet, "/users").ID; got != "getUsers" {
+					t.Errorf("operationId = %q, want getUsers", got)
+				}
+			},
+		},
+		{
+			name: "spec override",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodPost, "/custom", overriddenHandler{ghttp.NewJSONPayloadHandler(func(w http.ResponseWriter, r *http.Request, i testItem) (testItem, int) {
+					return i, http.StatusOK
+				})})
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				op := operation(t, doc, http.MethodPost, "/custom")
+				if got, want := marshal(t, op), marshal(t, overriddenHandler{}.SwaggerOperation()); got != want {
+					t.Errorf("operation = %s, want %s", got, want)
+				}
+				definition(t, doc, "testItem")
+			},
+		},
+		{
+			name: "security",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/private", respond[string](ghttp.WithSecurityRequirements(map[string][]string{"bearer": nil}, map[string][]string{"key": {}})))
+				r.Method(http.MethodGet, "/public", respond[string]())
+			},
+			opts: []SpecOption{WithBearerSecurity("bearer"), WithAPIKeySecurity("key", "api_key", "query")},
+			check: func(t *testing.T, doc spec.Swagger) {
+				bearer, key := doc.SecurityDefinitions["bearer"], doc.SecurityDefinitions["key"]
+				if bearer == nil || bearer.Type != "apiKey" || bearer.Name != "Authorization" || bearer.In != "header" {
+					t.Errorf("bearer = %s", marshal(t, bearer))
+				}
+				if key == nil || key.Type != "apiKey" || key.Name != "api_key" || key.In != "query" {
+					t.Errorf("key = %s", marshal(t, key))
+				}
+				want := []map[string][]string{{"bearer": nil}, {"key": {}}}
+				if got := operation(t, doc, http.MethodGet, "/private").Security; !reflect.DeepEqual(got, want) {
+					t.Errorf("security = %v, want %v", got, want)
+				}
+				if got := operation(t, doc, http.MethodGet, "/public").Security; got != nil {
+					t.Errorf("public security = %v, want none", got)
+				}
+			},
+		},
+		{
+			name: "deprecated",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/old", ghttp.Deprecated(respond[string]()))
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				if got := marshal(t, operation(t, doc, http.MethodGet, "/old")); !strings.Contains(got, `"deprecated":true`) {
+					t.Errorf("operation = %s, want deprecated", got)
+				}
+			},
+		},
+		{
+			name: "webhooks",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/items", respond[testItem]())
+			},
+			opts: []SpecOption{
+				WithWebhookDocs([]WebhookDoc{
+					{EventName: "item.created", URL: "https://consumer.example.com/hooks", PayloadType: reflect.TypeOf(testItem{})},
+					{EventName: "item.deleted", PayloadType: reflect.TypeOf(testItemCopy{})},
+				}),
+				WithDefinitionNamer(func(t reflect.Type) string { return "Named" + t.Name() }),
+				WithDeduplicateSchemas(),
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				if _, ok := doc.Paths.Paths["https://consumer.example.com/hooks"]; ok {
+					t.Error("webhook documented as a path")
+				}
+				got := marshal(t, doc.Extensions[webhooksExtension])
+				for _, want := range []string{`"item.created":{"post":`, `"x-webhook-url":"https://consumer.example.com/hooks"`, `"$ref":"#/definitions/NamedtestItem"`} {
+					if !strings.Contains(got, want) {
+						t.Errorf("x-webhooks = %s, want it to contain %s", got, want)
+					}
+				}
+				if strings.Contains(got, `"$ref":"#/definitions/NamedtestItemCopy"`) {
+					t.Errorf("x-webhooks = %s, want the deduplicated NamedtestItem reference", got)
+				}
+			},
+		},
+		{
+			name: "sunset",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/old", respond[string](ghttp.WithSunset(time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC))))
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				if got := marshal(t, operation(t, doc, http.MethodGet, "/old")); !strings.Contains(got, `"x-sunset":"2027-01-01T00:00:00Z"`) {
+					t.Errorf("operation = %s, want x-sunset", got)
+				}
+			},
+		},
+		{
+			name: "external docs",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/things", linkedHandler{respond[string]()})
+			},
+			opts: []SpecOption{WithExternalDocs("https://example.com/docs", "Guide")},
+			check: func(t *testing.T, doc spec.Swagger) {
+				if got, want := marshal(t, doc.ExternalDocs), `{"description":"Guide","url":"https://example.com/docs"}`; got != want {
+					t.Errorf("document externalDocs = %s, want %s", got, want)
+				}
+				op := operation(t, doc, http.MethodGet, "/things")
+				if got, want := marshal(t, op.ExternalDocs), `{"description":"Things","url":"https://example.com/things"}`; got != want {
+					t.Errorf("operation externalDocs = %s, want %s", got, want)
+				}
+			},
+		},
+		{
+			name: "union",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/payment-method", respond[testPaymentMethod]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				def := definition(t, doc, "testPaymentMethod")
+				var refs []string
+				for _, s := range def.OneOf {
+					refs = append(refs, s.Ref.String())
+				}
+				if want := []string{"#/definitions/testCreditCard", "#/definitions/testBankTransfer"}; !reflect.DeepEqual(refs, want) {
+					t.Errorf("oneOf = %v, want %v", refs, want)
+				}
+				definition(t, doc, "testCreditCard")
+				definition(t, doc, "testBankTransfer")
+			},
+		},
+		{
+			name: "array constraints",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/arrays", respond[testArrays]())
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				def := definition(t, doc, "testArrays")
+				for _, tt := range []struct {
+					field              string
+					minItems, maxItems *int64
+					unique             bool
+				}{
+					{field: "tags", minItems: ptr[int64](1)},
+					{field: "ids", maxItems: ptr[int64](100)},
+					{field: "codes", unique: true},
+					{field: "all", minItems: ptr[int64](1), maxItems: ptr[int64](10), unique: true},
+				} {
+					property := def.Properties[tt.field]
+					if !reflect.DeepEqual(property.MinItems, tt.minItems) || !reflect.DeepEqual(property.MaxItems, tt.maxItems) || property.UniqueItems != tt.unique {
+						t.Errorf("%s = %s", tt.field, marshal(t, property))
+					}
+				}
+			},
+		},
+		{
+			name: "typed path params",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/users/{id}/{name}/{active}", respond[string](ghttp.WithPathParams[testUserParams]()))
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				types := map[string]string{}
+				for _, parameter := range operation(t, doc, http.MethodGet, "/users/{id}/{name}/{active}").Parameters {
+					if parameter.In == "path" {
+						types[parameter.Name] = parameter.Type + "/" + parameter.Format
+					}
+				}
+				if want := map[string]string{"id": "integer/int64", "name": "string/", "active": "boolean/"}; !reflect.DeepEqual(types, want) {
+					t.Errorf("path params = %v, want %v", types, want)
+				}
+			},
+		},
+		{
+			name: "streaming response",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/stream", ghttp.NewStreamingJSONHandler(func(w http.ResponseWriter, r *http.Request) (<-chan testItem, error) {
+					return nil, nil
+				}))
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				resp, ok := operation(t, doc, http.MethodGet, "/stream").Responses.StatusCodeResponses[http.StatusOK]
+				if !ok || resp.Schema == nil || resp.Schema.Ref.String() != "#/definitions/testItem" {
+					t.Errorf("200 response = %s, want a testItem", marshal(t, resp))
+				}
+			},
+		},
+		{
+			name: "server-sent events",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodGet, "/events", ghttp.NewSSEHandler(func(w http.ResponseWriter, r *http.Request) (<-chan ghttp.SSEEvent[testItem], error) {
+					return nil, nil
+				}))
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				op := operation(t, doc, http.MethodGet, "/events")
+				if want := []string{"text/event-stream"}; !reflect.DeepEqual(op.Produces, want) {
+					t.Errorf("produces = %v, want %v", op.Produces, want)
+				}
+				if got := op.Extensions["x-streaming"]; got != true {
+					t.Errorf("x-streaming = %v, want true", got)
+				}
+				resp := op.Responses.StatusCodeResponses[http.StatusOK]
+				if resp.Schema == nil || resp.Schema.Ref.String() != "#/definitions/testItem" {
+					t.Errorf("200 response = %s, want the event data", marshal(t, resp))
+				}
+			},
+		},
+		{
+			name: "no content",
+			routes: func(r chi.Router) {
+				r.Method(http.MethodDelete, "/users/{id}", ghttp.NewNoContentHandler(func(w http.ResponseWriter, r *http.Request) error {
+					return nil
+				}))
+			},
+			check: func(t *testing.T, doc spec.Swagger) {
+				responses := operation(t, doc, http.MethodDelete, "/users/{id}").Responses.StatusCodeResponses
+				resp, ok := responses[http.StatusNoContent]
+				if !ok || resp.Schema != nil {
+					t.Errorf("204 response = %s, want one without a schema", marshal(t, resp))
+				}
+				if _, ok := responses[http.StatusOK]; ok {
+					t.Error("200 response documented for a NoContentHandler")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			tt.routes(r)
+			tt.check(t, Swagger(r, newConfig(tt.opts...)))
+		})
+	}
+}
+
+// nullable reports whether s has the Swagger 2.0 `x-nullable` extension.
+func nullable(s spec.Schema) bool {
+	v, _ := s.Extensions.GetBool("x-nullable")
+	return v
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func serveDoc(t *testing.T, h http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	return rec
+}
+
+func TestHandlerFuncYAML(t *testing.T) {
+	r := chi.NewRouter()
+	r.Method(http.MethodGet, "/users", respond[string]())
+	h := HandlerFuncWithOptions(r, WithYAMLSupport())
+
+	tests := []struct {
+		name        string
+		target      string
+		header      http.Header
+		contentType string
+	}{
+		{"query parameter", "/swagger?format=yaml", nil, "application/x-yaml"},
+		{"accept header", "/swagger", http.Header{"Accept": {"application/x-yaml"}}, "application/x-yaml"},
+		{"default", "/swagger", nil, "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveDoc(t, h, tt.target, tt.header)
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			var doc struct {
+				Swagger string                 `yaml:"swagger" json:"swagger"`
+				Paths   map[string]interface{} `yaml:"paths" json:"paths"`
+			}
+			if err := yaml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+			}
+			if _, ok := doc.Paths["/users"]; doc.Swagger != "2.0" || !ok {
+				t.Errorf("document = %s, want swagger 2.0 with /users", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerFuncDevRebuilds(t *testing.T) {
+	tests := []struct {
+		name string
+		h    func(r chi.Router) http.HandlerFunc
+		wait time.Duration
+	}{
+		{"dev", HandlerFuncDev, 0},
+		{"ttl", func(r chi.Router) http.HandlerFunc { return HandlerFuncWithTTL(r, time.Millisecond) }, 5 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Method(http.MethodGet, "/first", respond[string]())
+			h := tt.h(r)
+			paths := func() map[string]interface{} {
+				var doc struct {
+					Paths map[string]interface{} `json:"paths"`
+				}
+				if err := json.Unmarshal(serveDoc(t, h, "/swagger", nil).Body.Bytes(), &doc); err != nil {
+					t.Fatal(err)
+				}
+				return doc.Paths
+			}
+
+			if _, ok := paths()["/first"]; !ok {
+				t.Fatal("/first missing")
+			}
+			r.Method(http.MethodGet, "/second", respond[string]())
+			time.Sleep(tt.wait)
+			if _, ok := paths()["/second"]; !ok {
+				t.Error("/second missing after it was registered")
+			}
+		})
+	}
+}
+
+func TestMergeSpecs(t *testing.T) {
+	docWith := func(route string, defs spec.Definitions) spec.Swagger {
+		r := chi.NewRouter()
+		r.Method(http.MethodGet, route, respond[testItem]())
+		doc := Swagger(r, Config{})
+		for name, def := range defs {
+			doc.Definitions[name] = def
+		}
+		return doc
+	}
+
+	tests := []struct {
+		name    string
+		docs    []spec.Swagger
+		wantErr string
+		paths   []string
+	}{
+		{
+			name:  "distinct routes, shared definition",
+			docs:  []spec.Swagger{docWith("/users", nil), docWith("/orders", nil)},
+			paths: []string{"/orders", "/users"},
+		},
+		{
+			name:    "same route and method",
+			docs:    []spec.Swagger{docWith("/users", nil), docWith("/users", nil)},
+			wantErr: "GET /users",
+		},
+		{
+			name: "conflicting definitions",
+			docs: []spec.Swagger{
+				docWith("/users", nil),
+				docWith("/orders", spec.Definitions{"testItem": *spec.StringProperty()}),
+			},
+			wantErr: "testItem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := MergeSpecs(tt.docs...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one naming %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for route := range merged.Paths.Paths {
+				paths = append(paths, route)
+			}
+			slices.Sort(paths)
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+			if len(merged.Definitions) != 1 {
+				t.Errorf("definitions = %v, want testItem once", definitionNames(merged))
+			}
+		})
+	}
+}
+
+func TestRegisterInterfaceSchemaSliceElement(t *testing.T) {
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	RegisterInterfaceSchema[error](spec.StringProperty().WithDescription("Error message"))
+	t.Cleanup(func() {
+		delete(typeschema.InterfaceSchemas, errorType)
+	})
+	type errorList struct {
+		Errors []error `json:"errors"`
+	}
+
+	r := chi.NewRouter()
+	r.Method(http.MethodGet, "/errors", respond[errorList]())
+	doc := Swagger(r, Config{})
+
+	items := definition(t, doc, "errorList").Properties["errors"].Items
+	if items == nil || items.Schema == nil {
+		t.Fatal("errors has no item schema")
+	}
+	if got := items.Schema; !got.Type.Contains("string") || got.Description != "Error message" {
+		t.Errorf("errors items = %s, want the registered schema", marshal(t, got))
+	}
+}
+
+func TestWithAPIKeySecurityLocation(t *testing.T) {
+	for _, in := range []string{"header", "query", "cookie", "body"} {
+		t.Run(in, func(t *testing.T) {
+			defer func() {
+				if panicked, want := recover() != nil, in == "cookie" || in == "body"; panicked != want {
+					t.Errorf("panicked = %t, want %t", panicked, want)
+				}
+			}()
+			WithAPIKeySecurity("key", "api_key", in)
+		})
+	}
+}
+
+func TestStructParams(t *testing.T) {
+	tests := []struct {
+		name string
+		t    reflect.Type
+		want []string
+	}{
+		{"struct", reflect.TypeOf(testUserParams{}), []string{"id", "name", "active"}},
+		{"pointer to struct", reflect.TypeOf(&testUserParams{}), []string{"id", "name", "active"}},
+		{"string", reflect.TypeOf(""), nil},
+		{"pointer to string", reflect.TypeOf(ptr("")), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, parameter := range structParams(spec.Swagger{}, Config{}, tt.t, spec.PathParam, ghttp.PathParamTags...) {
+				got = append(got, parameter.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parameters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package chi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-openapi/spec"
+)
+
+// MergeSpecs merges the paths and definitions of docs into one document,
+// taking every other field from the first. It returns an error if two docs
+// document the same method of a route, or define different schemas under the
+// same name.
+func MergeSpecs(docs ...spec.Swagger) (spec.Swagger, error) {
+	if len(docs) == 0 {
+		return spec.Swagger{}, nil
+	}
+	merged := copyDoc(docs[0])
+	merged.Paths = &spec.Paths{Paths: map[string]spec.PathItem{}}
+	merged.Definitions = spec.Definitions{}
+	for _, doc := range docs {
+		doc = copyDoc(doc)
+		if doc.Paths != nil {
+			for route, item := range doc.Paths.Paths {
+				existing, ok := merged.Paths.Paths[route]
+				if !ok {
+					merged.Paths.Paths[route] = item
+					continue
+				}
+				slots := operationSlots(&existing)
+				for method, op := range operationSlots(&item) {
+					if *op == nil {
+						continue
+					}
+					if *slots[method] != nil {
+						return spec.Swagger{}, fmt.Errorf("merging specs: %s %s is documented more than once", method, route)
+					}
+					*slots[method] = *op
+				}
+				merged.Paths.Paths[route] = existing
+			}
+		}
+		for name, def := range doc.Definitions {
+			existing, ok := merged.Definitions[name]
+			if ok && !sameSchema(existing, def) {
+				return spec.Swagger{}, fmt.Errorf("merging specs: definition %s has conflicting schemas", name)
+			}
+			merged.Definitions[name] = def
+		}
+	}
+	return merged, nil
+}
+
+func sameSchema(a, b spec.Schema) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
+
+// HandlerFuncMerged serves the document merged with MergeSpecs from the
+// documents of routers. It is built once, on the first request; if merging
+// fails, every request gets a 500.
+func HandlerFuncMerged(routers ...chi.Router) http.HandlerFunc {
+	docFn := sync.OnceValues(func() (spec.Swagger, error) {
+		docs := make([]spec.Swagger, len(routers))
+		for i, r := range routers {
+			docs[i] = initializeDoc(r, Config{})
+		}
+		return MergeSpecs(docs...)
+	})
+	return func(w http.ResponseWriter, req *http.Request) {
+		doc, err := docFn()
+		if err != nil {
+			ghttp.Logger().Error("merging docs", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		jsonHandlerFunc(func() spec.Swagger { return doc })(w, req)
+	}
+}
@@ -0,0 +1,86 @@
+package chi
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"ghttp"
+	"ghttp/swaggerui"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-openapi/spec"
+)
+
+var (
+	redocTemplate = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>ReDoc</title>
+</head>
+<body>
+  <redoc spec-url="{{ .SpecURL }}"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`))
+)
+
+// docsHandler marks the routes registered by Mount so they are left out of
+// the document they serve.
+type docsHandler struct {
+	http.HandlerFunc
+}
+
+// Mount registers the Swagger JSON for r at docsPath, Swagger UI at
+// docsPath+"/ui" and ReDoc at docsPath+"/redoc". Swagger UI is served by
+// package swaggerui; ReDoc is loaded from its CDN.
+func Mount(r chi.Router, docsPath string, cfg Config) {
+	mount(r, docsPath, docFunc(r, cfg))
+}
+
+// MountWithYAML is Mount that also serves the spec as YAML at
+// docsPath+"/yaml".
+func MountWithYAML(r chi.Router, docsPath string, cfg Config) {
+	docFn := docFunc(r, cfg)
+	mount(r, docsPath, docFn)
+	r.Method(http.MethodGet, docsPath+"/yaml", docsHandler{yamlHandlerFunc(docFn)})
+}
+
+func mount(r chi.Router, docsPath string, docFn func() spec.Swagger) {
+	r.Method(http.MethodGet, docsPath, docsHandler{jsonHandlerFunc(docFn)})
+	ui := swaggerui.Handler(docsPath, docsPath+"/ui")
+	r.Method(http.MethodGet, docsPath+"/ui", docsHandler{ui})
+	r.Method(http.MethodGet, docsPath+"/ui/assets/*", docsHandler{ui})
+	r.Method(http.MethodGet, docsPath+"/redoc", docsHandler{templateHandlerFunc(redocTemplate, docsPath)})
+}
+
+func yamlHandlerFunc(docFn func() spec.Swagger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		doc := docFn()
+		if tag := req.URL.Query().Get("tag"); tag != "" {
+			doc = FilterByTag(doc, tag)
+		}
+		b, err := YAML(doc)
+		if err != nil {
+			ghttp.Logger().Error("encoding doc", slog.Any("error", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(b)
+	}
+}
+
+func templateHandlerFunc(tmpl *template.Template, specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := tmpl.Execute(w, struct{ SpecURL string }{specURL}); err != nil {
+			ghttp.Logger().Error("rendering template", slog.String("template", tmpl.Name()), slog.Any("error", err))
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package chi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// URLParam returns the chi URL parameter name converted to T. Strings are
+// returned as is, numbers and bools are parsed with strconv and types such as
+// uuid.UUID through encoding.TextUnmarshaler. On failure it returns the zero
+// value and an error naming the parameter.
+func URLParam[T any](r *http.Request, name string) (T, error) {
+	v, err := ghttp.ParseParam[T](chi.URLParam(r, name))
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("invalid URL param %q: %w", name, err)
+	}
+	return v, nil
+}
+
+// PathParams populates the struct T from the chi URL parameters of r. Fields
+// are matched by their ghttp.PathParamTags, falling back to the lowercased
+// field name, as when they are documented with ghttp.WithPathParams, and
+// converted as by URLParam. Errors name the offending field.
+func PathParams[T any](r *http.Request) (T, error) {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		var zero T
+		return zero, errors.New("binding path parameters: no chi route context")
+	}
+	return ghttp.BindParams[T](func(name string) (string, bool) {
+		for i, key := range rctx.URLParams.Keys {
+			if key == name {
+				return rctx.URLParams.Values[i], true
+			}
+		}
+		return "", false
+	}, ghttp.PathParamTags...)
+}
@@ -0,0 +1,46 @@
+package chi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestPathParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		want    testUserParams
+		wantErr string
+	}{
+		{"valid", "/users/42/ada/true", testUserParams{ID: 42, Name: "ada", Active: true}, ""},
+		{"invalid int", "/users/x/ada/true", testUserParams{}, "ID"},
+		{"invalid bool", "/users/42/ada/maybe", testUserParams{}, "Active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testUserParams
+			var err error
+			r := chi.NewRouter()
+			r.Get("/users/{id}/{name}/{active}", func(w http.ResponseWriter, req *http.Request) {
+				got, err = PathParams[testUserParams](req)
+			})
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one naming %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("params = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
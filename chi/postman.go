@@ -0,0 +1,49 @@
+package chi
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+type postmanEnvironment struct {
+	Name   string                    `json:"name"`
+	Values []postmanEnvironmentValue `json:"values"`
+	Scope  string                    `json:"_postman_variable_scope"`
+}
+
+type postmanEnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// PostmanEnvironment produces a Postman Environment with a `base_url`
+// variable set to baseURL, followed by variables in key order.
+func PostmanEnvironment(baseURL string, variables map[string]string) ([]byte, error) {
+	env := postmanEnvironment{
+		Name:   baseURL,
+		Values: []postmanEnvironmentValue{newPostmanEnvironmentValue("base_url", baseURL)},
+		Scope:  "environment",
+	}
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		if key != "base_url" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env.Values = append(env.Values, newPostmanEnvironmentValue(key, variables[key]))
+	}
+	return json.Marshal(env)
+}
+
+func newPostmanEnvironmentValue(key string, value string) postmanEnvironmentValue {
+	return postmanEnvironmentValue{
+		Key:     key,
+		Value:   value,
+		Type:    "default",
+		Enabled: true,
+	}
+}
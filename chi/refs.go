@@ -0,0 +1,168 @@
+package chi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+const definitionsPrefix = "#/definitions/"
+
+var (
+	refEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	refUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// refProperty returns a schema referencing the definition called name,
+// escaping it as a JSON pointer token.
+func refProperty(name string) *spec.Schema {
+	return spec.RefProperty(definitionsPrefix + refEscaper.Replace(name))
+}
+
+// refName returns the definition name referenced by s, if any.
+func refName(s *spec.Schema) string {
+	ref := s.Ref.String()
+	if !strings.HasPrefix(ref, definitionsPrefix) {
+		return ""
+	}
+	return refUnescaper.Replace(strings.TrimPrefix(ref, definitionsPrefix))
+}
+
+func operations(item spec.PathItem) []*spec.Operation {
+	var ops []*spec.Operation
+	for _, op := range []*spec.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// operationSlots returns the operation fields of item by method, so they can
+// be replaced.
+func operationSlots(item *spec.PathItem) map[string]**spec.Operation {
+	return map[string]**spec.Operation{
+		http.MethodGet:     &item.Get,
+		http.MethodPut:     &item.Put,
+		http.MethodPost:    &item.Post,
+		http.MethodDelete:  &item.Delete,
+		http.MethodOptions: &item.Options,
+		http.MethodHead:    &item.Head,
+		http.MethodPatch:   &item.Patch,
+	}
+}
+
+// walkSchema calls fn for s and every schema nested inside it.
+func walkSchema(s *spec.Schema, fn func(*spec.Schema)) {
+	if s == nil {
+		return
+	}
+	fn(s)
+	if s.Items != nil {
+		walkSchema(s.Items.Schema, fn)
+		for i := range s.Items.Schemas {
+			walkSchema(&s.Items.Schemas[i], fn)
+		}
+	}
+	for name, property := range s.Properties {
+		walkSchema(&property, fn)
+		s.Properties[name] = property
+	}
+	for name, property := range s.PatternProperties {
+		walkSchema(&property, fn)
+		s.PatternProperties[name] = property
+	}
+	if s.AdditionalProperties != nil {
+		walkSchema(s.AdditionalProperties.Schema, fn)
+	}
+	if s.AdditionalItems != nil {
+		walkSchema(s.AdditionalItems.Schema, fn)
+	}
+	for _, schemas := range [][]spec.Schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for i := range schemas {
+			walkSchema(&schemas[i], fn)
+		}
+	}
+	walkSchema(s.Not, fn)
+}
+
+// walkOperationSchemas calls fn for every schema used by the parameters and
+// responses of op.
+func walkOperationSchemas(op *spec.Operation, fn func(*spec.Schema)) {
+	for i := range op.Parameters {
+		walkSchema(op.Parameters[i].Schema, fn)
+	}
+	if op.Responses == nil {
+		return
+	}
+	if op.Responses.Default != nil {
+		walkSchema(op.Responses.Default.Schema, fn)
+	}
+	for _, resp := range op.Responses.StatusCodeResponses {
+		walkSchema(resp.Schema, fn)
+	}
+}
+
+// walkDocSchemas calls fn for every schema in the definitions, paths and
+// webhooks of doc.
+func walkDocSchemas(doc spec.Swagger, fn func(*spec.Schema)) {
+	for name, def := range doc.Definitions {
+		walkSchema(&def, fn)
+		doc.Definitions[name] = def
+	}
+	for _, item := range webhookItems(doc) {
+		for _, op := range operations(*item) {
+			walkOperationSchemas(op, fn)
+		}
+	}
+	if doc.Paths == nil {
+		return
+	}
+	for _, item := range doc.Paths.Paths {
+		for _, op := range operations(item) {
+			walkOperationSchemas(op, fn)
+		}
+	}
+}
+
+// dedupeDefinitions merges definitions with identical schemas into the
+// lexically smallest name and points every reference at it. Merging can make
+// further definitions identical, so it repeats until nothing changes.
+func dedupeDefinitions(doc spec.Swagger) {
+	for {
+		names := make([]string, 0, len(doc.Definitions))
+		for name := range doc.Definitions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		renames := map[string]string{}
+		canonical := map[string]string{}
+		for _, name := range names {
+			b, err := json.Marshal(doc.Definitions[name])
+			if err != nil {
+				continue
+			}
+			if c, ok := canonical[string(b)]; ok {
+				renames[name] = c
+				continue
+			}
+			canonical[string(b)] = name
+		}
+		if len(renames) == 0 {
+			return
+		}
+
+		for name := range renames {
+			delete(doc.Definitions, name)
+		}
+		walkDocSchemas(doc, func(s *spec.Schema) {
+			if c, ok := renames[refName(s)]; ok {
+				s.Ref = refProperty(c).Ref
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package chi
+
+import (
+	"net/http"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SDKClientHint is the client and method a generated SDK should expose an
+// operation as.
+type SDKClientHint struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+}
+
+// GenerateSDKHints returns the hints of every route in r whose handler
+// implements ghttp.ClientSDKHint, keyed by "METHOD /route".
+func GenerateSDKHints(r chi.Router) map[string]SDKClientHint {
+	hints := map[string]SDKClientHint{}
+	chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if hint, ok := sdkHint(handler); ok {
+			hints[method+" "+route] = hint
+		}
+		return nil
+	})
+	return hints
+}
+
+func sdkHint(handler http.Handler) (SDKClientHint, bool) {
+	var hinter ghttp.ClientSDKHint
+	hinter, _ = handler.(ghttp.ClientSDKHint)
+	if hinter == nil || (hinter.SDKClientName() == "" && hinter.SDKClientMethod() == "") {
+		return SDKClientHint{}, false
+	}
+	return SDKClientHint{
+		Name:   hinter.SDKClientName(),
+		Method: hinter.SDKClientMethod(),
+	}, true
+}
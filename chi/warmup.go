@@ -0,0 +1,73 @@
+package chi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+)
+
+// Warmup builds the Swagger document for r, validates it against the Swagger
+// 2.0 specification, including that every reference in it resolves, and runs
+// the health check of every handler implementing ghttp.HealthChecker. Swagger
+// 2.0 requires an `info` block, so cfg must set at least WithTitle and
+// WithVersion. Call it before starting the server to turn spec
+// generation panics and unhealthy handlers into startup failures.
+func Warmup(r chi.Router, cfg Config) error {
+	var errs []error
+	doc, err := buildDoc(r, cfg)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, validateDoc(doc))
+	}
+	err = chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		var hChecker ghttp.HealthChecker
+		hChecker, _ = handler.(ghttp.HealthChecker)
+		if hChecker != nil {
+			if err := hChecker.Healthcheck(); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", method, route, err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// buildDoc runs initializeDoc, converting a panic into an error.
+func buildDoc(r chi.Router, cfg Config) (doc spec.Swagger, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("generating swagger doc: %v", rec)
+		}
+	}()
+	return initializeDoc(r, cfg), nil
+}
+
+// validateDoc validates doc with go-openapi/validate, as the Swagger
+// tooling consuming it would.
+func validateDoc(doc spec.Swagger) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("encoding swagger doc: %w", err)
+	}
+	analyzed, err := loads.Analyzed(b, "")
+	if err != nil {
+		return fmt.Errorf("loading swagger doc: %w", err)
+	}
+	if err := validate.Spec(analyzed, strfmt.Default); err != nil {
+		return fmt.Errorf("invalid swagger doc: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package chi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-openapi/spec"
+)
+
+// danglingHandler documents a response referencing a definition that does
+// not exist.
+type danglingHandler struct {
+	ghttp.JSONHandler[string]
+}
+
+func (danglingHandler) SwaggerOperation() *spec.Operation {
+	op := spec.NewOperation("dangling")
+	op.RespondsWith(http.StatusOK, spec.NewResponse().WithDescription("OK").WithSchema(spec.RefProperty("#/definitions/missing")))
+	return op
+}
+
+func TestWarmup(t *testing.T) {
+	info := []SpecOption{WithTitle("Test"), WithVersion("1.0.0")}
+	tests := []struct {
+		name    string
+		routes  func(r chi.Router)
+		opts    []SpecOption
+		wantErr string
+	}{
+		{"valid", func(r chi.Router) {
+			r.Method(http.MethodGet, "/items/{id}", respond[testItem](ghttp.WithPathParams[testUserParams]()))
+			r.Method(http.MethodGet, "/maps", respond[testMaps]())
+			r.Method(http.MethodGet, "/nodes", respond[testNode]())
+			r.Method(http.MethodGet, "/accounts", respond[[]testAccount]())
+		}, info, ""},
+		{"unresolved reference", func(r chi.Router) {
+			r.Method(http.MethodGet, "/dangling", danglingHandler{respond[string]()})
+		}, info, "missing"},
+		{"no info", func(r chi.Router) {
+			r.Method(http.MethodGet, "/items", respond[testItem]())
+		}, nil, ".info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			tt.routes(r)
+			err := Warmup(r, newConfig(tt.opts...))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Warmup() = %v, want no error", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Warmup() = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
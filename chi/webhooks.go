@@ -0,0 +1,68 @@
+package chi
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"ghttp"
+
+	"github.com/go-openapi/spec"
+)
+
+// webhooksExtension is the document extension holding the webhooks, since
+// Swagger 2.0 has no dedicated section for them. Like the `webhooks` of
+// OpenAPI 3.1, it maps each event name to the path item of the requests sent
+// for it.
+const webhooksExtension = "x-webhooks"
+
+// WebhookDoc describes a request the service sends to its consumers. Method
+// defaults to POST when empty.
+type WebhookDoc struct {
+	EventName   string
+	Method      string
+	URL         string
+	PayloadType reflect.Type
+}
+
+// addWebhooks documents cfg.Webhooks under the `x-webhooks` extension of doc,
+// keeping them apart from the paths the service serves. The URL a webhook is
+// sent to, if known, is documented as its operation's `x-webhook-url`.
+func addWebhooks(doc *spec.Swagger, cfg Config) {
+	if len(cfg.Webhooks) == 0 {
+		return
+	}
+	webhooks := map[string]*spec.PathItem{}
+	for _, webhook := range cfg.Webhooks {
+		method := webhook.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+		item, ok := webhooks[webhook.EventName]
+		if !ok {
+			item = &spec.PathItem{}
+		}
+		slot, ok := operationSlots(item)[method]
+		if !ok {
+			ghttp.Logger().Warn("Unsupported webhook method", slog.String("event", webhook.EventName), slog.String("method", method))
+			continue
+		}
+		operation := spec.NewOperation("").WithSummary(webhook.EventName)
+		if webhook.URL != "" {
+			operation.AddExtension("x-webhook-url", webhook.URL)
+		}
+		if webhook.PayloadType != nil {
+			operation.AddParam(spec.BodyParam(getName(cfg, webhook.PayloadType), typeRef(*doc, cfg, webhook.PayloadType)))
+		}
+		*slot = operation
+		webhooks[webhook.EventName] = item
+	}
+	doc.AddExtension(webhooksExtension, webhooks)
+}
+
+// webhookItems returns the path items added by addWebhooks, or nil if doc
+// has none, e.g. because it was decoded from JSON.
+func webhookItems(doc spec.Swagger) map[string]*spec.PathItem {
+	webhooks, _ := doc.Extensions[webhooksExtension].(map[string]*spec.PathItem)
+	return webhooks
+}
@@ -0,0 +1,39 @@
+// Package client provides http.RoundTripper decorators for outgoing requests
+// made while serving ghttp handlers.
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"ghttp/middleware"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// WithCorrelationID propagates the correlation ID stored in ctx by
+// middleware.CorrelationID to outgoing requests, in the header it was
+// received in. When ctx carries no ID, the context of each request is used.
+// A nil transport uses http.DefaultTransport.
+func WithCorrelationID(ctx context.Context, transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		idCtx := ctx
+		if middleware.CorrelationIDFromContext(idCtx) == "" {
+			idCtx = r.Context()
+		}
+		id := middleware.CorrelationIDFromContext(idCtx)
+		if id == "" || r.Header.Get(middleware.CorrelationIDHeaderFromContext(idCtx)) != "" {
+			return transport.RoundTrip(r)
+		}
+		r = r.Clone(r.Context())
+		r.Header.Set(middleware.CorrelationIDHeaderFromContext(idCtx), id)
+		return transport.RoundTrip(r)
+	})
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"net/http"
+)
+
+// Ed25519Sign signs the body of outgoing requests with privKey and sends the
+// base64-encoded signature in headerName, as middleware.Ed25519Verify expects.
+// A nil transport uses http.DefaultTransport.
+func Ed25519Sign(privKey ed25519.PrivateKey, headerName string) func(http.RoundTripper) http.RoundTripper {
+	return func(transport http.RoundTripper) http.RoundTripper {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				var err error
+				body, err = io.ReadAll(r.Body)
+				r.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+			}
+			r = r.Clone(r.Context())
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+			r.Header.Set(headerName, base64.StdEncoding.EncodeToString(ed25519.Sign(privKey, body)))
+			return transport.RoundTrip(r)
+		})
+	}
+}
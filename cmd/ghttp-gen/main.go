@@ -0,0 +1,199 @@
+// Command ghttp-gen writes the Swagger spec of a chi router to a file, for use
+// with go generate:
+//
+//	//go:generate ghttp-gen --router-pkg ./api --router-var Router --options-var SpecOptions --output swagger.json
+//
+// Options other than the title, version, host and base path flags are taken
+// from the []chi.SpecOption variable of the router package named by
+// --options-var; the flags take precedence over it.
+//
+// Go cannot load a package at runtime, so ghttp-gen writes a small program
+// importing the router package into a temporary directory of the current
+// module, runs it with `go run` and removes it again. The current module must
+// therefore depend on ghttp, imported as the module ghttp-gen was built from
+// unless --ghttp-module says otherwise.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"text/template"
+)
+
+var genTemplate = template.Must(template.New("main").Parse(`package main
+
+import (
+	{{- if ne .Format "yaml" }}
+	"encoding/json"
+	{{- end }}
+	"fmt"
+	"os"
+
+	ghttpchi {{ printf "%q" (print .GhttpModule "/chi") }}
+
+	router {{ printf "%q" .RouterImport }}
+)
+
+func main() {
+	var opts []ghttpchi.SpecOption
+{{- if .OptionsVar }}
+	opts = append(opts, router.{{ .OptionsVar }}...)
+{{- end }}
+{{- if .Title }}
+	opts = append(opts, ghttpchi.WithTitle({{ printf "%q" .Title }}))
+{{- end }}
+{{- if .Version }}
+	opts = append(opts, ghttpchi.WithVersion({{ printf "%q" .Version }}))
+{{- end }}
+{{- if .Host }}
+	opts = append(opts, ghttpchi.WithHost({{ printf "%q" .Host }}))
+{{- end }}
+{{- if .BasePath }}
+	opts = append(opts, ghttpchi.WithBasePath({{ printf "%q" .BasePath }}))
+{{- end }}
+	var cfg ghttpchi.Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	doc := ghttpchi.Swagger(router.{{ .RouterVar }}, cfg)
+{{- if eq .Format "yaml" }}
+	b, err := ghttpchi.YAML(doc)
+{{- else }}
+	b, err := json.MarshalIndent(doc, "", "  ")
+{{- end }}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "encoding spec: %s\n", err.Error())
+		os.Exit(1)
+	}
+	os.Stdout.Write(b)
+}
+`))
+
+type genParams struct {
+	// Dir is the directory of the module to generate the spec in, the
+	// current directory if empty.
+	Dir          string
+	GhttpModule  string
+	RouterImport string
+	RouterVar    string
+	OptionsVar   string
+	Format       string
+	Title        string
+	Version      string
+	Host         string
+	BasePath     string
+}
+
+// defaultGhttpModule returns the path of the module ghttp-gen was built from,
+// which is the module the generated program imports ghttp from.
+func defaultGhttpModule() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		return info.Main.Path
+	}
+	return "ghttp"
+}
+
+func main() {
+	routerPkg := flag.String("router-pkg", "", "package containing the chi router, e.g. ./api")
+	routerVar := flag.String("router-var", "", "exported variable holding the chi.Router")
+	optionsVar := flag.String("options-var", "", "exported []chi.SpecOption variable of the router package to apply")
+	ghttpModule := flag.String("ghttp-module", defaultGhttpModule(), "module path ghttp is imported as")
+	output := flag.String("output", "", "file to write the spec to (default stdout)")
+	format := flag.String("format", "json", "output format: json or yaml")
+	title := flag.String("title", "", "API title")
+	version := flag.String("version", "", "API version")
+	host := flag.String("host", "", "host serving the API")
+	basePath := flag.String("base-path", "", "path prefix of every route")
+	flag.Parse()
+
+	if *routerPkg == "" || *routerVar == "" {
+		fmt.Fprintln(os.Stderr, "ghttp-gen: --router-pkg and --router-var are required")
+		os.Exit(2)
+	}
+	if *format != "json" && *format != "yaml" {
+		fmt.Fprintf(os.Stderr, "ghttp-gen: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	b, err := generate(genParams{
+		GhttpModule:  *ghttpModule,
+		RouterImport: *routerPkg,
+		RouterVar:    *routerVar,
+		OptionsVar:   *optionsVar,
+		Format:       *format,
+		Title:        *title,
+		Version:      *version,
+		Host:         *host,
+		BasePath:     *basePath,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ghttp-gen: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(b)
+		return
+	}
+	if err := os.WriteFile(*output, b, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "ghttp-gen: writing %s: %s\n", *output, err.Error())
+		os.Exit(1)
+	}
+}
+
+func generate(params genParams) ([]byte, error) {
+	importPath, err := goList(params.Dir, params.RouterImport)
+	if err != nil {
+		return nil, err
+	}
+	params.RouterImport = importPath
+
+	moduleDir := params.Dir
+	if moduleDir == "" {
+		moduleDir = "."
+	}
+	dir, err := os.MkdirTemp(moduleDir, "ghttp-gen-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		return nil, err
+	}
+	if err := genTemplate.Execute(f, params); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Dir = params.Dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running generator: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// goList resolves a package pattern such as ./api to its import path in the
+// module in dir.
+func goList(dir string, pkg string) (string, error) {
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", pkg, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+// fixtureModule writes a module depending on this copy of ghttp, holding the
+// router in testdata/api, and returns its directory.
+func fixtureModule(t *testing.T) string {
+	t.Helper()
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	goMod := "module fixture\n\ngo 1.22.0\n\nrequire ghttp v0.0.0\n\nreplace ghttp => " + root + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for src, dst := range map[string]string{
+		filepath.Join(root, "go.sum"): filepath.Join(dir, "go.sum"),
+		"testdata/api/api.go":         filepath.Join(dir, "api", "api.go"),
+	} {
+		b, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+	// The fixture module has no requirements of its own beyond ghttp.
+	t.Setenv("GOFLAGS", "-mod=mod")
+	dir := fixtureModule(t)
+
+	b, err := generate(genParams{
+		Dir:          dir,
+		GhttpModule:  "ghttp",
+		RouterImport: "./api",
+		RouterVar:    "Router",
+		OptionsVar:   "SpecOptions",
+		Format:       "json",
+		Version:      "1.0.0",
+		BasePath:     "/v1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc spec.Swagger
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+	if _, ok := doc.Paths.Paths["/items"]; !ok {
+		t.Errorf("paths = %v, want /items", doc.Paths.Paths)
+	}
+	if doc.Info == nil || doc.Info.Title != "Items" || doc.Info.Version != "1.0.0" {
+		t.Errorf("info = %+v, want the title of the options variable and the version flag", doc.Info)
+	}
+	if doc.Host != "api.example.com" || doc.BasePath != "/v1" {
+		t.Errorf("host, base path = %q, %q, want %q, %q", doc.Host, doc.BasePath, "api.example.com", "/v1")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if matched, _ := filepath.Match("ghttp-gen-*", entry.Name()); matched {
+			t.Errorf("temporary directory %s left behind", entry.Name())
+		}
+	}
+}
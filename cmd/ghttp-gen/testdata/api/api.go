@@ -0,0 +1,29 @@
+// Package api is the router ghttp-gen is tested against, copied into a
+// module of its own by the test.
+package api
+
+import (
+	"net/http"
+
+	"ghttp"
+	ghttpchi "ghttp/chi"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Item struct {
+	Name string `json:"name"`
+}
+
+var Router = chi.NewRouter()
+
+var SpecOptions = []ghttpchi.SpecOption{
+	ghttpchi.WithTitle("Items"),
+	ghttpchi.WithHost("api.example.com"),
+}
+
+func init() {
+	Router.Method(http.MethodGet, "/items", ghttp.NewJSONHandler(func(w http.ResponseWriter, r *http.Request) ([]Item, http.Header, int) {
+		return nil, nil, http.StatusOK
+	}))
+}
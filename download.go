@@ -0,0 +1,53 @@
+package ghttp
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// FileDownloadHandlerFunc returns the file to send, its name and content
+// type, and the status code. body may be nil for a response without one.
+type FileDownloadHandlerFunc func(http.ResponseWriter, *http.Request) (body io.ReadCloser, filename string, contentType string, status int)
+
+// FileDownloadHandler streams the file its handler function returns as an
+// attachment, without buffering it in memory.
+type FileDownloadHandler struct {
+	handlerFn FileDownloadHandlerFunc
+	opts      handlerOptions
+}
+
+func NewFileDownloadHandler(fn FileDownloadHandlerFunc, opts ...HandlerOption) FileDownloadHandler {
+	return FileDownloadHandler{
+		handlerFn: fn,
+		opts:      newHandlerOptions(opts...),
+	}
+}
+
+func (h FileDownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h FileDownloadHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, filename, contentType, statusCode := h.handlerFn(w, r)
+	if body == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+	defer body.Close()
+	if filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, quoteEscaper.Replace(filename)))
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(statusCode)
+	if _, err := io.Copy(w, body); err != nil {
+		Logger().Error("streaming file", slog.String("filename", filename), slog.Any("error", err))
+		return
+	}
+}
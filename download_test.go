@@ -0,0 +1,45 @@
+package ghttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestFileDownloadHandler(t *testing.T) {
+	const content = "first line\nsecond line\nthird line\n"
+	body := &closeRecorder{Reader: strings.NewReader(content)}
+	h := NewFileDownloadHandler(func(w http.ResponseWriter, r *http.Request) (io.ReadCloser, string, string, int) {
+		return body, `report "q1".txt`, "text/plain", http.StatusOK
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got, want := rec.Header().Get("Content-Disposition"), `attachment; filename="report \"q1\".txt"`; got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/plain" {
+		t.Errorf("Content-Type = %q, want text/plain", got)
+	}
+	if got := rec.Body.String(); got != content {
+		t.Errorf("body = %q, want %q", got, content)
+	}
+	if !body.closed {
+		t.Error("body was not closed")
+	}
+}
@@ -0,0 +1,94 @@
+package ghttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// FormBind populates the struct T from the parsed form of r, including the
+// query string. Fields are matched by their `form` tag, falling back to the
+// lowercased field name.
+func FormBind[T any](r *http.Request) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() != reflect.Struct {
+		return v, fmt.Errorf("binding form: %s is not a struct", rv.Type())
+	}
+	if err := r.ParseForm(); err != nil {
+		return v, fmt.Errorf("binding form: %w", err)
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		name := ParamName(f, "form")
+		if name == "" || !r.Form.Has(name) {
+			continue
+		}
+		if err := setValue(rv.Field(i), r.Form.Get(name)); err != nil {
+			return v, fmt.Errorf("binding form field %q: %w", name, err)
+		}
+	}
+	return v, nil
+}
+
+type FormPayloadHandlerFunc[I any, O any] func(http.ResponseWriter, *http.Request, I) (O, int)
+
+// FormPayloadHandler is a JSONPayloadHandler for
+// application/x-www-form-urlencoded request bodies, bound to I with FormBind.
+type FormPayloadHandler[I any, O any] struct {
+	handlerFunc FormPayloadHandlerFunc[I, O]
+	opts        handlerOptions
+}
+
+func NewFormPayloadHandler[I any, O any](fn FormPayloadHandlerFunc[I, O], opts ...HandlerOption) FormPayloadHandler[I, O] {
+	h := FormPayloadHandler[I, O]{
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+	h.opts.checkProcessTypes(h.PayloadType(), h.ResponseType())
+	return h
+}
+
+func (h FormPayloadHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h FormPayloadHandler[I, O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload I
+	err := h.opts.readBody(w, r, func(body io.Reader) error {
+		// ParseForm reads r.Body, so it has to see the limited body.
+		r.Body = io.NopCloser(body)
+		var err error
+		payload, err = FormBind[I](r)
+		return err
+	})
+	if err != nil {
+		h.opts.writeDecodeError(w, err)
+		return
+	}
+	if err := h.opts.runPreprocess(r.Context(), &payload); err != nil {
+		h.opts.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	out, code := h.handlerFunc(w, r, payload)
+	if err := h.opts.runPostprocess(r.Context(), &out, code); err != nil {
+		h.opts.writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	h.opts.writeJSON(w, code, out)
+}
+
+func (h FormPayloadHandler[I, O]) PayloadType() reflect.Type {
+	return reflect.TypeOf((*I)(nil)).Elem()
+}
+
+func (h FormPayloadHandler[I, O]) ResponseType() reflect.Type {
+	return reflect.TypeOf((*O)(nil)).Elem()
+}
+
+func (h FormPayloadHandler[I, O]) Consumes() []string {
+	return []string{formContentType}
+}
@@ -1,20 +1,33 @@
 package ghttp
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"reflect"
+	"strings"
+	"time"
 )
 
 var (
 	defaultInvalidJSONPayloadHandler InvalidJSONPayloadHandler = func(err error) (interface{}, int) {
-		return "Invalid payload", http.StatusBadGateway
+		return errorResponse{Error: "Invalid payload"}, http.StatusBadRequest
 	}
 )
 
+// InvalidJSONPayloadHandler returns the response body and status code sent
+// when a request body cannot be decoded.
 type InvalidJSONPayloadHandler func(err error) (interface{}, int)
 
+// SetDefaultInvalidJSONPayloadHandler replaces the response sent by every
+// payload handler when the request body is not valid JSON for its payload
+// type. The default responds with a 400 Bad Request and `{"error": "Invalid
+// payload"}`, since the client sent the malformed body; use fn to return a
+// 422 or a more detailed error instead.
+// It is not safe for concurrent use and should be called during
+// initialization.
 func SetDefaultInvalidJSONPayloadHandler(fn InvalidJSONPayloadHandler) {
 	defaultInvalidJSONPayloadHandler = fn
 }
@@ -27,63 +40,490 @@ type PayloadTyper interface {
 	PayloadType() reflect.Type
 }
 
-type JSONHandlerFunc[O any] func(http.ResponseWriter, *http.Request) (O, int)
+// ResponseHeaderTyper is implemented by handlers that document the headers
+// sent with each response status code.
+type ResponseHeaderTyper interface {
+	ResponseHeadersForStatus() map[int]map[string]reflect.Type
+}
+
+// OperationIDer is implemented by handlers that choose their own operationId
+// instead of the one generated from the method and path.
+type OperationIDer interface {
+	OperationID() string
+}
+
+// Tagger is implemented by handlers that name the tags their operation is
+// grouped under, instead of the tag derived from the path.
+type Tagger interface {
+	Tags() []string
+}
+
+// Describer is implemented by handlers that document a summary and
+// description of their operation.
+type Describer interface {
+	Summary() string
+	Description() string
+}
+
+// SecurityRequirer is implemented by handlers that require authentication.
+// Each requirement maps security scheme names to the scopes needed; any one
+// of the requirements is sufficient.
+type SecurityRequirer interface {
+	SecurityRequirements() []map[string][]string
+}
+
+// Deprecator is implemented by handlers whose operation is deprecated.
+type Deprecator interface {
+	IsDeprecated() bool
+}
+
+// Sunsetter is implemented by handlers whose operation will be removed at a
+// known time. A zero time means no sunset is planned.
+type Sunsetter interface {
+	SunsetDate() time.Time
+}
+
+// ExternalDocsLinker is implemented by handlers whose operation is documented
+// further elsewhere.
+type ExternalDocsLinker interface {
+	ExternalDocs() (url string, description string)
+}
+
+// Consumer is implemented by handlers whose request body is not JSON. A
+// handler consuming application/x-www-form-urlencoded has its PayloadType
+// documented as formData parameters instead of a body.
+type Consumer interface {
+	Consumes() []string
+}
+
+// Producer is implemented by handlers whose response body is not JSON, such
+// as a stream of server-sent events, naming its content types.
+type Producer interface {
+	Produces() []string
+}
+
+// HeaderAdder is implemented by handlers that read request headers, naming
+// each so it is documented as a header parameter.
+type HeaderAdder interface {
+	HeaderAdd() []string
+}
+
+// HMACWebhook is implemented by webhook receivers whose requests are signed
+// with HMAC-SHA256, naming the header carrying the signature.
+type HMACWebhook interface {
+	WebhookSignatureHeader() string
+}
+
+// ClientSDKHint is implemented by handlers that name the client and method
+// generated SDKs should expose them as.
+type ClientSDKHint interface {
+	SDKClientName() string
+	SDKClientMethod() string
+}
+
+// WSDocumenter is implemented by WebSocket handlers to document the types of
+// the messages they receive and send.
+type WSDocumenter interface {
+	WSMessageType() (in reflect.Type, out reflect.Type)
+}
+
+// TimeoutProvider is implemented by handlers that give up after a fixed
+// duration, so clients know how long to wait for a response.
+type TimeoutProvider interface {
+	HandlerTimeout() time.Duration
+}
+
+// HealthChecker is implemented by handlers that can report whether their
+// dependencies are usable, e.g. at startup.
+type HealthChecker interface {
+	Healthcheck() error
+}
+
+// PushResource is a resource a handler wants pushed to HTTP/2 clients ahead of
+// its response. As is the preload destination, e.g. "script" or "style".
+type PushResource struct {
+	URL string
+	As  string
+}
+
+type Pusher interface {
+	PushResources() []PushResource
+}
+
+// push pushes the resources declared by h when w supports HTTP/2 server push.
+// push sets a `Link` preload header for each of the resources of h, and
+// pushes them when w supports server push. Most browsers have disabled push,
+// so http.ErrNotSupported is expected and not logged.
+func push(w http.ResponseWriter, h Pusher) {
+	pusher, _ := w.(http.Pusher)
+	for _, res := range h.PushResources() {
+		if res.As != "" {
+			w.Header().Add("Link", fmt.Sprintf("<%s>; rel=preload; as=%s", res.URL, res.As))
+		}
+		if pusher == nil {
+			continue
+		}
+		if err := pusher.Push(res.URL, nil); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			Logger().Error("pushing resource", slog.String("url", res.URL), slog.Any("error", err))
+		}
+	}
+}
+
+// JSONHandlerFunc returns the response body, any response headers and the
+// status code. Headers are copied onto the response by the handler, so the
+// function does not need to touch the http.ResponseWriter itself.
+type JSONHandlerFunc[O any] func(http.ResponseWriter, *http.Request) (O, http.Header, int)
+
+// LegacyJSONHandlerFunc is the JSONHandlerFunc signature from before response
+// headers were returned. Use NewLegacyJSONHandler to serve one.
+type LegacyJSONHandlerFunc[O any] func(http.ResponseWriter, *http.Request) (O, int)
 
 type JSONHandler[O any] struct {
 	handlerFn JSONHandlerFunc[O]
+	opts      handlerOptions
 }
 
-func NewJSONHandler[O any](fn JSONHandlerFunc[O]) JSONHandler[O] {
+func NewJSONHandler[O any](fn JSONHandlerFunc[O], opts ...HandlerOption) JSONHandler[O] {
 	return JSONHandler[O]{
 		handlerFn: fn,
+		opts:      newHandlerOptions(opts...),
 	}
 }
 
+func NewLegacyJSONHandler[O any](fn LegacyJSONHandlerFunc[O], opts ...HandlerOption) JSONHandler[O] {
+	return NewJSONHandler(func(w http.ResponseWriter, r *http.Request) (O, http.Header, int) {
+		resp, statusCode := fn(w, r)
+		return resp, nil, statusCode
+	}, opts...)
+}
+
 func (h JSONHandler[O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	resp, statusCode := h.handlerFn(w, r)
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONHandler[O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	push(w, h)
+	resp, headers, statusCode := h.handlerFn(w, r)
+	copyHeaders(w.Header(), headers)
+	h.opts.writeJSON(w, statusCode, resp)
+}
+
+func copyHeaders(dst http.Header, src http.Header) {
+	for key, values := range src {
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
+func (h JSONHandler[O]) ResponseType() reflect.Type {
+	var v O
+	return reflect.TypeOf(v)
+}
+
+func (h JSONHandler[O]) PushResources() []PushResource {
+	return h.opts.pushResources
+}
+
+func (h JSONHandler[O]) HandlerTimeout() time.Duration {
+	return h.opts.timeout
+}
+
+func (h JSONHandler[O]) QueryParamType() reflect.Type {
+	return h.opts.queryParamType
+}
+
+func (h JSONHandler[O]) PathParamType() reflect.Type {
+	return h.opts.pathParamType
+}
+
+func (h JSONHandler[O]) SecurityRequirements() []map[string][]string {
+	return h.opts.securityRequirements
+}
+
+func (h JSONHandler[O]) ResponseHeadersForStatus() map[int]map[string]reflect.Type {
+	return h.opts.responseHeaders
+}
+
+func (h JSONHandler[O]) SunsetDate() time.Time {
+	return h.opts.sunset
+}
+
+// WithHeaders is a JSONHandler that requires the request headers named in
+// Headers, documenting them without a handler type of its own.
+type WithHeaders[O any] struct {
+	JSONHandler[O]
+	Headers []string
+}
+
+func (h WithHeaders[O]) HeaderAdd() []string {
+	return h.Headers
+}
+
+// TaggedHandler is a JSONHandler grouped under fixed tags.
+type TaggedHandler[O any] struct {
+	JSONHandler[O]
+	tags []string
+}
+
+// WithTags groups h under tags in the generated document.
+func WithTags[O any](tags []string, h JSONHandler[O]) TaggedHandler[O] {
+	return TaggedHandler[O]{JSONHandler: h, tags: tags}
+}
+
+func (h TaggedHandler[O]) Tags() []string {
+	return h.tags
+}
+
+// DescribedHandler is a JSONHandler with a fixed summary and description.
+type DescribedHandler[O any] struct {
+	JSONHandler[O]
+	summary     string
+	description string
+}
+
+// WithDescription documents h with summary and desc.
+func WithDescription[O any](summary string, desc string, h JSONHandler[O]) DescribedHandler[O] {
+	return DescribedHandler[O]{JSONHandler: h, summary: summary, description: desc}
+}
+
+func (h DescribedHandler[O]) Summary() string {
+	return h.summary
+}
+
+func (h DescribedHandler[O]) Description() string {
+	return h.description
+}
+
+// DeprecatedHandler is a JSONHandler documented as deprecated.
+type DeprecatedHandler[O any] struct {
+	JSONHandler[O]
+}
+
+// Deprecated documents h as deprecated.
+func Deprecated[O any](h JSONHandler[O]) DeprecatedHandler[O] {
+	return DeprecatedHandler[O]{JSONHandler: h}
+}
+
+func (h DeprecatedHandler[O]) IsDeprecated() bool {
+	return true
+}
+
+// JSONHandlerFuncE is a JSONHandlerFunc that reports failures with an error
+// instead of in O.
+type JSONHandlerFuncE[O any] func(http.ResponseWriter, *http.Request) (O, int, error)
+
+// JSONHandlerE is a JSONHandler for a JSONHandlerFuncE. When the function
+// returns an error, `{"error": err.Error()}` is sent with its status code in
+// place of O.
+type JSONHandlerE[O any] struct {
+	JSONHandler[O]
+	handlerFnE JSONHandlerFuncE[O]
+}
+
+func NewJSONHandlerE[O any](fn JSONHandlerFuncE[O], opts ...HandlerOption) JSONHandlerE[O] {
+	return JSONHandlerE[O]{
+		JSONHandler: JSONHandler[O]{opts: newHandlerOptions(opts...)},
+		handlerFnE:  fn,
+	}
+}
+
+func (h JSONHandlerE[O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONHandlerE[O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	push(w, h)
+	resp, statusCode, err := h.handlerFnE(w, r)
+	if err != nil {
+		h.opts.writeError(w, statusCode, err.Error())
+		return
+	}
+	h.opts.writeJSON(w, statusCode, resp)
+}
+
+// errorResponse is the body of error responses, matching the default error
+// response documented by the chi package.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError responds with statusCode and `{"error": msg}`.
+func (o handlerOptions) writeError(w http.ResponseWriter, statusCode int, msg string) {
+	o.writeJSON(w, statusCode, errorResponse{Error: msg})
+}
+
+// writeDecodeError responds to a request whose body could not be decoded
+// with a 408 or 413 when reading it failed, including past the limit of an
+// http.MaxBytesReader, or with the default invalid payload response.
+func (o handlerOptions) writeDecodeError(w http.ResponseWriter, err error) {
+	switch {
+	case isBodyReadTimeout(err):
+		o.writeError(w, http.StatusRequestTimeout, http.StatusText(http.StatusRequestTimeout))
+	case errors.Is(err, errBodyTooLarge), errors.As(err, new(*http.MaxBytesError)):
+		o.writeError(w, http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge))
+	default:
+		resp, statusCode := defaultInvalidJSONPayloadHandler(err)
+		o.writeJSON(w, statusCode, resp)
+	}
+}
+
+// writeJSON responds with statusCode, replaced as configured by
+// WithSuccessStatus, and v encoded as JSON.
+func (o handlerOptions) writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	enc := json.NewEncoder(w)
-	if err := enc.Encode(resp); err != nil {
-		fmt.Printf("encoding response body: %+v\n", err)
+	w.WriteHeader(o.resolveStatus(statusCode))
+	enc := o.newEncoder(w)
+	if err := enc.Encode(v); err != nil {
+		Logger().Error("encoding response body", slog.Any("error", err))
 		return
 	}
 }
 
-func (h JSONHandler[O]) ResponseType() reflect.Type {
+type NoContentHandlerFunc func(http.ResponseWriter, *http.Request) error
+
+// NoContentHandler responds with a 204 No Content and no body, or with the
+// default invalid payload response when its handler function fails.
+type NoContentHandler struct {
+	handlerFn NoContentHandlerFunc
+	opts      handlerOptions
+}
+
+func NewNoContentHandler(fn NoContentHandlerFunc, opts ...HandlerOption) NoContentHandler {
+	return NoContentHandler{
+		handlerFn: fn,
+		opts:      newHandlerOptions(opts...),
+	}
+}
+
+func (h NoContentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h NoContentHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	err := h.handlerFn(w, r)
+	if err == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	resp, statusCode := defaultInvalidJSONPayloadHandler(err)
+	h.opts.writeJSON(w, statusCode, resp)
+}
+
+// ResponseType returns struct{}, which is documented as a 204 without a
+// schema.
+func (h NoContentHandler) ResponseType() reflect.Type {
+	return reflect.TypeOf(struct{}{})
+}
+
+// JSONContextHandlerFunc is a JSONHandlerFunc that receives the request
+// context instead of the http.ResponseWriter.
+type JSONContextHandlerFunc[O any] func(context.Context, *http.Request) (O, int)
+
+type JSONContextHandler[O any] struct {
+	handlerFn JSONContextHandlerFunc[O]
+	opts      handlerOptions
+}
+
+func NewJSONContextHandler[O any](fn JSONContextHandlerFunc[O], opts ...HandlerOption) JSONContextHandler[O] {
+	return JSONContextHandler[O]{
+		handlerFn: fn,
+		opts:      newHandlerOptions(opts...),
+	}
+}
+
+func (h JSONContextHandler[O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONContextHandler[O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	resp, statusCode := h.handlerFn(r.Context(), r)
+	h.opts.writeJSON(w, statusCode, resp)
+}
+
+func (h JSONContextHandler[O]) ResponseType() reflect.Type {
 	var v O
 	return reflect.TypeOf(v)
 }
 
+func (h JSONContextHandler[O]) HandlerTimeout() time.Duration {
+	return h.opts.timeout
+}
+
+func (h JSONContextHandler[O]) QueryParamType() reflect.Type {
+	return h.opts.queryParamType
+}
+
+func (h JSONContextHandler[O]) PathParamType() reflect.Type {
+	return h.opts.pathParamType
+}
+
+func (h JSONContextHandler[O]) SecurityRequirements() []map[string][]string {
+	return h.opts.securityRequirements
+}
+
+func (h JSONContextHandler[O]) ResponseHeadersForStatus() map[int]map[string]reflect.Type {
+	return h.opts.responseHeaders
+}
+
+func (h JSONContextHandler[O]) SunsetDate() time.Time {
+	return h.opts.sunset
+}
+
 type JSONPayloadHandlerFunc[I any, O any] func(http.ResponseWriter, *http.Request, I) (O, int)
 
 type JSONPayloadHandler[I any, O any] struct {
 	handlerFunc JSONPayloadHandlerFunc[I, O]
+	opts        handlerOptions
 }
 
-func NewJSONPayloadHandler[I any, O any](fn JSONPayloadHandlerFunc[I, O]) JSONPayloadHandler[I, O] {
-	return JSONPayloadHandler[I, O]{
+func NewJSONPayloadHandler[I any, O any](fn JSONPayloadHandlerFunc[I, O], opts ...HandlerOption) JSONPayloadHandler[I, O] {
+	h := JSONPayloadHandler[I, O]{
 		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
 	}
+	h.opts.checkProcessTypes(h.PayloadType(), h.ResponseType())
+	return h
 }
 
 func (h JSONPayloadHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var resp interface{} // resp will be `O` if using `handlerFunc`
-	var statusCode int
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONPayloadHandler[I, O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	var payload I
-	dec := json.NewDecoder(r.Body)
-	if err := dec.Decode(&payload); err == nil {
-		resp, statusCode = h.handlerFunc(w, r, payload)
-	} else {
-		resp, statusCode = defaultInvalidJSONPayloadHandler(err)
+	if h.opts.requireContentType && !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.opts.writeError(w, http.StatusUnsupportedMediaType, "expected Content-Type: application/json")
+		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	enc := json.NewEncoder(w)
-	if err := enc.Encode(resp); err != nil {
-		fmt.Printf("encoding response body: %+v\n", err)
+	if err := h.opts.decodeBody(w, r, &payload); err != nil {
+		h.opts.writeDecodeError(w, err)
+		return
+	}
+	if err := h.opts.runPreprocess(r.Context(), &payload); err != nil {
+		h.opts.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	select {
+	case <-r.Context().Done():
+		// The client is gone, so skip the handler and write no response.
 		return
+	default:
 	}
+	out, code := h.handlerFunc(w, r, payload)
+	if err := h.opts.runPostprocess(r.Context(), &out, code); err != nil {
+		h.opts.writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	h.opts.writeJSON(w, code, out)
+}
+
+func (h JSONPayloadHandler[I, O]) PayloadType() reflect.Type {
+	return reflect.TypeOf((*I)(nil)).Elem()
+}
+
+func (h JSONPayloadHandler[I, O]) ResponseType() reflect.Type {
+	return reflect.TypeOf((*O)(nil)).Elem()
 }
 
 func (h JSONPayloadHandlerFunc[I, O]) PayloadType() reflect.Type {
@@ -95,3 +535,55 @@ func (h JSONPayloadHandlerFunc[I, O]) ResponseType() reflect.Type {
 	var v O
 	return reflect.TypeOf(v)
 }
+
+func (h JSONPayloadHandler[I, O]) HandlerTimeout() time.Duration {
+	return h.opts.timeout
+}
+
+func (h JSONPayloadHandler[I, O]) QueryParamType() reflect.Type {
+	return h.opts.queryParamType
+}
+
+func (h JSONPayloadHandler[I, O]) PathParamType() reflect.Type {
+	return h.opts.pathParamType
+}
+
+func (h JSONPayloadHandler[I, O]) SecurityRequirements() []map[string][]string {
+	return h.opts.securityRequirements
+}
+
+func (h JSONPayloadHandler[I, O]) ResponseHeadersForStatus() map[int]map[string]reflect.Type {
+	return h.opts.responseHeaders
+}
+
+func (h JSONPayloadHandler[I, O]) SunsetDate() time.Time {
+	return h.opts.sunset
+}
+
+func (h JSONPayloadHandler[I, O]) WebhookSignatureHeader() string {
+	return h.opts.webhookSignatureHeader
+}
+
+func (h JSONHandler[O]) SDKClientName() string {
+	return h.opts.sdkClientName
+}
+
+func (h JSONHandler[O]) SDKClientMethod() string {
+	return h.opts.sdkClientMethod
+}
+
+func (h JSONContextHandler[O]) SDKClientName() string {
+	return h.opts.sdkClientName
+}
+
+func (h JSONContextHandler[O]) SDKClientMethod() string {
+	return h.opts.sdkClientMethod
+}
+
+func (h JSONPayloadHandler[I, O]) SDKClientName() string {
+	return h.opts.sdkClientName
+}
+
+func (h JSONPayloadHandler[I, O]) SDKClientMethod() string {
+	return h.opts.sdkClientMethod
+}
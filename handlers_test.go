@@ -0,0 +1,75 @@
+package ghttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONPayloadHandlerMalformedBody(t *testing.T) {
+	called := false
+	h := NewJSONPayloadHandler(func(w http.ResponseWriter, r *http.Request, p struct{ Name string }) (string, int) {
+		called = true
+		return p.Name, http.StatusOK
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": `)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if called {
+		t.Error("handler function called for a malformed body")
+	}
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response %q: %v", rec.Body.String(), err)
+	}
+	if body.Error != "Invalid payload" {
+		t.Errorf("error = %q, want %q", body.Error, "Invalid payload")
+	}
+}
+
+func TestNoContentHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		want     int
+		wantBody bool
+	}{
+		{"success", nil, http.StatusNoContent, false},
+		{"error", errors.New("not found"), http.StatusBadRequest, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewNoContentHandler(func(w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Body.Len() > 0; got != tt.wantBody {
+				t.Errorf("body = %q, want body: %t", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestJSONPayloadHandlerMaxBytesReader(t *testing.T) {
+	h := NewJSONPayloadHandler(func(w http.ResponseWriter, r *http.Request, s string) (string, int) {
+		return s, http.StatusOK
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`"abcdef"`))
+	req.Body = http.MaxBytesReader(rec, req.Body, 4)
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
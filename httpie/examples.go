@@ -0,0 +1,155 @@
+// Package httpie generates HTTPie command line examples from a Swagger
+// document.
+package httpie
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// Examples returns an HTTPie command for every operation in swagger, keyed by
+// "METHOD /path". Body fields use the schema example when set and the zero
+// value of their type otherwise; operations with formData parameters are sent
+// as a form with `-f`.
+func Examples(swagger spec.Swagger, baseURL string) map[string]string {
+	examples := map[string]string{}
+	if swagger.Paths == nil {
+		return examples
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	for path, item := range swagger.Paths.Paths {
+		for method, op := range map[string]*spec.Operation{
+			http.MethodGet:     item.Get,
+			http.MethodPut:     item.Put,
+			http.MethodPost:    item.Post,
+			http.MethodDelete:  item.Delete,
+			http.MethodOptions: item.Options,
+			http.MethodHead:    item.Head,
+			http.MethodPatch:   item.Patch,
+		} {
+			if op != nil {
+				examples[method+" "+path] = command(swagger, method, baseURL+path, op)
+			}
+		}
+	}
+	return examples
+}
+
+func command(swagger spec.Swagger, method string, url string, op *spec.Operation) string {
+	args := []string{"http"}
+	var items []string
+	form := false
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "query":
+			items = append(items, param.Name+"=="+paramValue(param.Type))
+		case "header":
+			items = append(items, param.Name+":"+paramValue(param.Type))
+		case "formData":
+			form = true
+			items = append(items, param.Name+"="+paramValue(param.Type))
+		case "body":
+			items = append(items, bodyItems(swagger, param.Schema)...)
+		}
+	}
+	if form {
+		args = append(args, "-f")
+	}
+	args = append(args, method, url)
+	for _, item := range items {
+		args = append(args, quote(item))
+	}
+	return strings.Join(args, " ")
+}
+
+// bodyItems returns a request item per property of the object schema s,
+// following a `$ref` to its definition.
+func bodyItems(swagger spec.Swagger, s *spec.Schema) []string {
+	s = resolve(swagger, s)
+	if s == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var items []string
+	for _, name := range names {
+		property := s.Properties[name]
+		value := exampleValue(swagger, &property)
+		if str, ok := value.(string); ok {
+			items = append(items, name+"="+str)
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		items = append(items, name+":="+string(b))
+	}
+	return items
+}
+
+func resolve(swagger spec.Swagger, s *spec.Schema) *spec.Schema {
+	ref := s.Ref.String()
+	if ref == "" {
+		return s
+	}
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+	def, ok := swagger.Definitions[name]
+	if !ok {
+		return nil
+	}
+	return &def
+}
+
+func exampleValue(swagger spec.Swagger, s *spec.Schema) interface{} {
+	if s.Example != nil {
+		return s.Example
+	}
+	s = resolve(swagger, s)
+	if s == nil || len(s.Type) == 0 {
+		if s != nil && len(s.Properties) > 0 {
+			return map[string]interface{}{}
+		}
+		return nil
+	}
+	switch s.Type[0] {
+	case "string":
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		return []interface{}{}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func paramValue(tpe string) string {
+	switch tpe {
+	case "integer", "number":
+		return "0"
+	case "boolean":
+		return "false"
+	default:
+		return ""
+	}
+}
+
+// quote wraps item in single quotes when the shell would otherwise split or
+// expand it.
+func quote(item string) string {
+	if item != "" && !strings.ContainsAny(item, " \t\n'\"$`\\!*?[]{}()<>|&;#~") {
+		return item
+	}
+	return "'" + strings.ReplaceAll(item, "'", `'\''`) + "'"
+}
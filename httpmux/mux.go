@@ -0,0 +1,111 @@
+// Package httpmux documents ghttp handlers registered on a Go 1.22
+// http.ServeMux, using the same handler interfaces as package ghttp/chi.
+package httpmux
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	ghttpchi "ghttp/chi"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Mux is an http.ServeMux that records the routes registered on it, so they
+// can be documented.
+type Mux struct {
+	*http.ServeMux
+
+	mu     sync.Mutex
+	routes []route
+}
+
+// methods are the methods chi routes, and so the ones Mux can document.
+var methods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+type route struct {
+	method  string
+	path    string
+	handler http.Handler
+}
+
+func NewMux() *Mux {
+	return &Mux{ServeMux: http.NewServeMux()}
+}
+
+// Handle registers handler for pattern, as http.ServeMux.Handle does.
+// Patterns without a method are documented as GET. Like chi, Handle panics
+// for methods other than the standard ones, which could not be documented.
+func (m *Mux) Handle(pattern string, handler http.Handler) {
+	method, path := splitPattern(pattern)
+	if !methods[method] {
+		panic(fmt.Sprintf("httpmux: unsupported method %q in pattern %q", method, pattern))
+	}
+	m.ServeMux.Handle(pattern, handler)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = append(m.routes, route{method: method, path: path, handler: handler})
+}
+
+// HandleFunc registers fn for pattern, as http.ServeMux.HandleFunc does.
+func (m *Mux) HandleFunc(pattern string, fn func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(fn))
+}
+
+// SpecHandler serves the Swagger document of the recorded routes. The
+// document is built on the first request and rebuilt when routes have been
+// registered since, so it always includes every route.
+func (m *Mux) SpecHandler(opts ...ghttpchi.SpecOption) http.HandlerFunc {
+	var mu sync.Mutex
+	var spec http.HandlerFunc
+	built := -1
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if n := m.routeCount(); n != built {
+			spec = ghttpchi.HandlerFuncWithOptions(m.router(), opts...)
+			built = n
+		}
+		spec := spec
+		mu.Unlock()
+		spec(w, r)
+	}
+}
+
+func (m *Mux) routeCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.routes)
+}
+
+// router registers the recorded routes on a chi router, which is only walked
+// to generate the document and never serves requests.
+func (m *Mux) router() chi.Router {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := chi.NewRouter()
+	for _, rt := range m.routes {
+		r.Method(rt.method, rt.path, rt.handler)
+	}
+	return r
+}
+
+// splitPattern returns the method and path of a ServeMux pattern such as
+// "GET example.com/files/{path...}", rewriting its wildcards in chi syntax.
+func splitPattern(pattern string) (string, string) {
+	method := http.MethodGet
+	if m, rest, ok := strings.Cut(pattern, " "); ok {
+		method, pattern = m, strings.TrimLeft(rest, " \t")
+	}
+	if i := strings.Index(pattern, "/"); i > 0 {
+		pattern = pattern[i:] // Drop the host.
+	}
+	pattern = strings.ReplaceAll(pattern, "{$}", "")
+	pattern = strings.ReplaceAll(pattern, "...}", "}")
+	return method, pattern
+}
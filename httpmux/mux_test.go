@@ -0,0 +1,48 @@
+package httpmux
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ghttp"
+
+	"github.com/go-openapi/spec"
+)
+
+func hello(w http.ResponseWriter, r *http.Request) (string, http.Header, int) {
+	return "hello", nil, http.StatusOK
+}
+
+func TestSpecHandlerIncludesLaterRoutes(t *testing.T) {
+	m := NewMux()
+	m.Handle("GET /hello", ghttp.NewJSONHandler(hello))
+	specHandler := m.SpecHandler()
+
+	paths := func() map[string]spec.PathItem {
+		rec := httptest.NewRecorder()
+		specHandler(rec, httptest.NewRequest(http.MethodGet, "/swagger.json", nil))
+		var doc spec.Swagger
+		if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("decoding spec %q: %v", rec.Body.String(), err)
+		}
+		return doc.Paths.Paths
+	}
+	if _, ok := paths()["/hello"]; !ok {
+		t.Fatal("/hello missing from the spec")
+	}
+	m.Handle("POST /items/{id}", ghttp.NewJSONHandler(hello))
+	if _, ok := paths()["/items/{id}"]; !ok {
+		t.Error("/items/{id} registered after the first request missing from the spec")
+	}
+}
+
+func TestHandleRejectsUnsupportedMethods(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Handle did not panic for PURGE")
+		}
+	}()
+	NewMux().Handle("PURGE /cache", ghttp.NewJSONHandler(hello))
+}
@@ -0,0 +1,124 @@
+package typeschema
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"ghttp"
+
+	"github.com/go-openapi/spec"
+)
+
+// stringFormats are the string formats defined by OpenAPI and JSON Schema.
+var stringFormats = map[string]bool{
+	"byte": true, "binary": true, "date": true, "date-time": true, "time": true,
+	"duration": true, "password": true, "email": true, "idn-email": true,
+	"hostname": true, "idn-hostname": true, "ipv4": true, "ipv6": true,
+	"uri": true, "uri-reference": true, "iri": true, "iri-reference": true,
+	"uri-template": true, "uuid": true, "regex": true, "json-pointer": true,
+}
+
+// applyConstraints sets the validation constraints listed in the `openapi`
+// tag of f on its schema, e.g. `openapi:"min=0,max=100,pattern=^[a-z]+$"`
+// or `openapi:"minItems=1,uniqueItems=true"` for slices and arrays, and
+// `openapi:"format=email"` for strings. Since patterns may contain commas,
+// pattern takes the rest of the tag and must come last.
+// Invalid constraints are logged and skipped.
+func applyConstraints(schema *spec.Schema, f reflect.StructField) {
+	tag := f.Tag.Get("openapi")
+	if tag == "" {
+		return
+	}
+	for _, constraint := range splitConstraints(tag) {
+		key, value, _ := strings.Cut(constraint, "=")
+		if err := applyConstraint(schema, key, value); err != nil {
+			ghttp.Logger().Warn("Invalid openapi tag constraint",
+				slog.String("field", f.Name),
+				slog.String("constraint", constraint),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// splitConstraints splits tag at commas up to a pattern constraint, which is
+// kept whole.
+func splitConstraints(tag string) []string {
+	var constraints []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "pattern=") {
+			return append(constraints, tag)
+		}
+		constraint, rest, _ := strings.Cut(tag, ",")
+		constraints = append(constraints, constraint)
+		tag = rest
+	}
+	return constraints
+}
+
+func applyConstraint(schema *spec.Schema, key string, value string) error {
+	switch key {
+	case "min":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("min must be a number: %w", err)
+		}
+		schema.WithMinimum(n, false)
+	case "max":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("max must be a number: %w", err)
+		}
+		schema.WithMaximum(n, false)
+	case "minLength":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("minLength must be an integer: %w", err)
+		}
+		schema.WithMinLength(n)
+	case "maxLength":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("maxLength must be an integer: %w", err)
+		}
+		schema.WithMaxLength(n)
+	case "pattern":
+		schema.WithPattern(value)
+	case "format":
+		if !schema.Type.Contains("string") {
+			return fmt.Errorf("format only applies to strings")
+		}
+		schema.Format = value
+		if !stringFormats[value] {
+			return fmt.Errorf("unknown string format %q, used as is", value)
+		}
+	case "minItems", "maxItems":
+		if !schema.Type.Contains("array") {
+			return fmt.Errorf("%s only applies to arrays", key)
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer: %w", key, err)
+		}
+		if key == "minItems" {
+			schema.WithMinItems(n)
+		} else {
+			schema.WithMaxItems(n)
+		}
+	case "uniqueItems":
+		if !schema.Type.Contains("array") {
+			return fmt.Errorf("%s only applies to arrays", key)
+		}
+		unique, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("uniqueItems must be a boolean: %w", err)
+		}
+		schema.UniqueItems = unique
+	default:
+		return fmt.Errorf("unknown constraint %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+// Package typeschema builds JSON schemas for Go types by reflection. It is
+// shared by the Swagger 2.0 and OpenAPI 3 generators, which differ only in
+// where definitions are stored and referenced.
+package typeschema
+
+import (
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"ghttp"
+
+	"github.com/go-openapi/spec"
+)
+
+var (
+	// InterfaceSchemas documents values of the interface types they key.
+	InterfaceSchemas = map[reflect.Type]spec.Schema{}
+	// TypeFormatters documents the types named by their reflect.Type.String.
+	TypeFormatters = map[string]func() *spec.Schema{
+		"big.Int":         func() *spec.Schema { return spec.StrFmtProperty("bigint") },
+		"big.Float":       func() *spec.Schema { return spec.StrFmtProperty("bigdecimal") },
+		"decimal.Decimal": func() *spec.Schema { return spec.StrFmtProperty("decimal") },
+	}
+	refEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+	timeType   = reflect.TypeOf(time.Time{})
+	urlType    = reflect.TypeOf(url.URL{})
+
+	enumValuerType = reflect.TypeOf((*ghttp.EnumValuer)(nil)).Elem()
+	unionTyperType = reflect.TypeOf((*ghttp.UnionTyper)(nil)).Elem()
+)
+
+// Generator adds the schemas of Go types to Definitions, referencing them
+// as RefPrefix followed by their definition name.
+type Generator struct {
+	Definitions spec.Definitions
+	RefPrefix   string
+	// DefinitionNamer names the definition of t, defaulting to its type name.
+	DefinitionNamer func(t reflect.Type) string
+	// NullableExtension marks nullable schemas with the `x-nullable`
+	// extension of Swagger 2.0, which has no `nullable` keyword, instead of
+	// the OpenAPI 3 keyword.
+	NullableExtension bool
+}
+
+// Ref returns a schema referencing the definition called name, escaping it as
+// a JSON pointer token.
+func (g Generator) Ref(name string) *spec.Schema {
+	return spec.RefProperty(g.RefPrefix + refEscaper.Replace(name))
+}
+
+// TypeRef adds the definition of t and returns a schema referencing it.
+// Unnamed slices and arrays are documented as arrays of their element's
+// definition instead, and unnamed string-keyed maps as objects whose
+// additional properties reference it.
+func (g Generator) TypeRef(t reflect.Type) *spec.Schema {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Name() == "" && t.Elem().Kind() != reflect.Uint8 {
+			return spec.ArrayProperty(g.TypeRef(t.Elem()))
+		}
+	case reflect.Map:
+		if t.Name() == "" && t.Key().Kind() == reflect.String {
+			return spec.MapProperty(g.TypeRef(t.Elem()))
+		}
+	}
+	g.AddDefinition(t)
+	return g.Ref(g.Name(t))
+}
+
+func (g Generator) AddDefinition(t reflect.Type) {
+	name := g.Name(t)
+	if _, ok := g.Definitions[name]; ok {
+		return
+	}
+	// Reserve the name while the schema is built, so mutually recursive types
+	// reference it instead of adding it again.
+	g.Definitions[name] = spec.Schema{}
+	prop := g.Property(t)
+	if prop != nil {
+		g.Definitions[name] = *prop
+	} else {
+		delete(g.Definitions, name)
+	}
+}
+
+func (g Generator) Name(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + g.Name(t.Elem())
+	default:
+		if g.DefinitionNamer != nil {
+			return g.DefinitionNamer(t)
+		}
+		return strings.ReplaceAll(t.Name(), "/", ".")
+	}
+}
+
+// KnownProperty returns the schema of types that are documented as a whole
+// rather than by their kind, or nil for any other type.
+func KnownProperty(t reflect.Type) *spec.Schema {
+	if formatter, ok := TypeFormatters[t.String()]; ok {
+		property := formatter()
+		property.AddExtension("x-go-type", t.String())
+		return property
+	}
+	switch t {
+	case timeType:
+		return spec.DateTimeProperty()
+	case urlType:
+		return spec.StrFmtProperty("uri")
+	}
+	switch t.String() {
+	case "uuid.UUID":
+		return spec.StrFmtProperty("uuid")
+	case "date.DateString":
+		return spec.DateProperty()
+	}
+	return nil
+}
+
+func (g Generator) Property(t reflect.Type) *spec.Schema {
+	return g.property(t, map[reflect.Type]struct{}{})
+}
+
+// property builds the schema of t, listing its values when it implements
+// ghttp.EnumValuer.
+func (g Generator) property(t reflect.Type, visited map[reflect.Type]struct{}) *spec.Schema {
+	if union := g.unionProperty(t); union != nil {
+		return union
+	}
+	schema := g.kindProperty(t, visited)
+	if schema != nil && t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface && t.Implements(enumValuerType) {
+		schema.Enum = reflect.Zero(t).Interface().(ghttp.EnumValuer).EnumValues()
+	}
+	return schema
+}
+
+// unionProperty returns a `oneOf` or `anyOf` schema referencing the types of
+// a ghttp.UnionTyper, adding their definitions, or nil for any other type.
+func (g Generator) unionProperty(t reflect.Type) *spec.Schema {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface {
+		return nil
+	}
+	var unionTyper ghttp.UnionTyper
+	if t.Implements(unionTyperType) {
+		unionTyper = reflect.Zero(t).Interface().(ghttp.UnionTyper)
+	} else if reflect.PointerTo(t).Implements(unionTyperType) {
+		unionTyper = reflect.New(t).Interface().(ghttp.UnionTyper)
+	} else {
+		return nil
+	}
+	types, oneOf := unionTyper.UnionTypes()
+	refs := make([]spec.Schema, 0, len(types))
+	for _, ut := range types {
+		refs = append(refs, *g.TypeRef(ut))
+	}
+	if oneOf {
+		return &spec.Schema{SchemaProps: spec.SchemaProps{OneOf: refs}}
+	}
+	return &spec.Schema{SchemaProps: spec.SchemaProps{AnyOf: refs}}
+}
+
+// kindProperty builds the schema of t from its kind. visited holds the named
+// structs whose schema is being built, so a type referring back to one of
+// them, such as a tree node, is documented as a reference instead of
+// recursing forever.
+func (g Generator) kindProperty(t reflect.Type, visited map[reflect.Type]struct{}) *spec.Schema {
+	if property := KnownProperty(t); property != nil {
+		return property
+	}
+	switch t.Kind() {
+	//case reflect.Invalid:
+	case reflect.Bool:
+		return spec.BooleanProperty()
+	case reflect.Int:
+		return spec.Int64Property()
+	case reflect.Int8:
+		return spec.Int8Property()
+	case reflect.Int16:
+		return spec.Int16Property()
+	case reflect.Int32:
+		return spec.Int32Property()
+	case reflect.Int64:
+		return spec.Int64Property()
+	case reflect.Uint:
+		return spec.Int64Property()
+	case reflect.Uint8:
+		return spec.Int8Property()
+	case reflect.Uint16:
+		return spec.Int16Property()
+	case reflect.Uint32:
+		return spec.Int32Property()
+	case reflect.Uint64:
+		return spec.Int64Property()
+	//case reflect.Uintptr:
+	case reflect.Float32:
+		return spec.Float32Property()
+	case reflect.Float64:
+		return spec.Float64Property()
+	//case reflect.Complex64:
+	//case reflect.Complex128:
+	case reflect.Array:
+		return spec.ArrayProperty(g.refOrProperty(t.Elem(), visited))
+	//case reflect.Chan:
+	//case reflect.Func:
+	case reflect.Interface:
+		if schema, ok := InterfaceSchemas[t]; ok {
+			return &schema
+		}
+		return &spec.Schema{}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			ghttp.Logger().Warn("Unsupported map key kind for swagger property", slog.String("kind", t.Key().Kind().String()), slog.String("type_name", t.String()))
+			return nil
+		}
+		return spec.MapProperty(g.refOrProperty(t.Elem(), visited))
+	case reflect.Pointer:
+		if ref := g.structRef(t.Elem(), visited); ref != nil {
+			// Wrap the reference, as its definition itself is not nullable.
+			return g.nullable(&spec.Schema{SchemaProps: spec.SchemaProps{AllOf: []spec.Schema{*ref}}})
+		}
+		property := g.property(t.Elem(), visited)
+		if property != nil {
+			g.nullable(property)
+		}
+		return property
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings.
+			return spec.StrFmtProperty("byte")
+		}
+		return spec.ArrayProperty(g.refOrProperty(t.Elem(), visited))
+	case reflect.String:
+		return spec.StringProperty()
+	case reflect.Struct:
+		if t.Name() != "" {
+			if _, ok := visited[t]; ok {
+				return g.Ref(g.Name(t))
+			}
+			visited[t] = struct{}{}
+			defer delete(visited, t)
+		}
+		schema := spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Properties: spec.SchemaProperties{},
+			},
+		}
+		var allOf []spec.Schema
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			// encoding/json promotes the fields of embedded structs, and
+			// encodes other embedded types as fields named after the type.
+			if f.Anonymous && f.Tag.Get("json") == "" && indirect(f.Type).Kind() == reflect.Struct {
+				if ref := g.structRef(f.Type, visited); ref != nil {
+					allOf = append(allOf, *ref)
+					continue
+				}
+				if embedded := g.property(f.Type, visited); embedded != nil {
+					for name, property := range embedded.SchemaProps.Properties {
+						schema.SchemaProps.Properties[name] = property
+					}
+					schema.SchemaProps.Required = append(schema.SchemaProps.Required, embedded.SchemaProps.Required...)
+					allOf = append(allOf, embedded.SchemaProps.AllOf...)
+				}
+				continue
+			}
+			name, required := jsonField(f)
+			if name == "" {
+				continue
+			}
+			property := g.refOrProperty(f.Type, visited)
+			if property != nil {
+				applyConstraints(property, f)
+				schema.SchemaProps.Properties[name] = *property
+				if required {
+					schema.SchemaProps.Required = append(schema.SchemaProps.Required, name)
+				}
+			}
+		}
+		if len(allOf) > 0 {
+			return spec.ComposedSchema(append(allOf, schema)...).Typed("object", "")
+		}
+		return &schema
+	//case reflect.UnsafePointer:
+	default:
+		ghttp.Logger().Warn("Unknown kind for swagger property", slog.String("kind", t.Kind().String()), slog.String("type_name", g.Name(t)))
+		return nil
+	}
+}
+
+// nullable marks schema as nullable and returns it.
+func (g Generator) nullable(schema *spec.Schema) *spec.Schema {
+	if g.NullableExtension {
+		schema.AddExtension("x-nullable", true)
+	} else {
+		schema.Nullable = true
+	}
+	return schema
+}
+
+// jsonField returns the name encoding/json gives the field f, or "" when it
+// is skipped, and whether it is required, i.e. not tagged `omitempty`.
+func jsonField(f reflect.StructField) (string, bool) {
+	if !f.IsExported() && !(f.Anonymous && indirect(f.Type).Kind() == reflect.Struct) {
+		return "", false
+	}
+	opts := strings.Split(f.Tag.Get("json"), ",")
+	name := opts[0]
+	if name == "-" && len(opts) == 1 {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range opts[1:] {
+		if opt == "omitempty" {
+			return name, false
+		}
+	}
+	return name, true
+}
+
+// indirect returns the type t points to, or t if it is not a pointer.
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// refOrProperty documents named structs, such as the types of fields and
+// elements, by a reference to their definition, and other types inline.
+func (g Generator) refOrProperty(t reflect.Type, visited map[reflect.Type]struct{}) *spec.Schema {
+	if t.Kind() == reflect.Struct {
+		if ref := g.structRef(t, visited); ref != nil {
+			return ref
+		}
+	}
+	return g.property(t, visited)
+}
+
+// structRef returns a reference to the definition of a named struct, or a
+// pointer to one, adding the definition. It returns nil for other types,
+// which are documented inline instead.
+func (g Generator) structRef(t reflect.Type, visited map[reflect.Type]struct{}) *spec.Schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" || KnownProperty(t) != nil {
+		return nil
+	}
+	if _, ok := visited[t]; !ok {
+		g.AddDefinition(t)
+	}
+	return g.Ref(g.Name(t))
+}
@@ -0,0 +1,141 @@
+package typeschema
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+type mutualA struct {
+	B *mutualB
+}
+
+type mutualB struct {
+	A *mutualA
+}
+
+func newTestGenerator() Generator {
+	return Generator{Definitions: spec.Definitions{}, RefPrefix: "#/definitions/"}
+}
+
+func TestAddDefinitionMutualRecursion(t *testing.T) {
+	g := newTestGenerator()
+	g.AddDefinition(reflect.TypeOf(mutualA{}))
+
+	for name, field := range map[string]string{"mutualA": "B", "mutualB": "A"} {
+		def, ok := g.Definitions[name]
+		if !ok {
+			t.Fatalf("definition %s missing", name)
+		}
+		property, ok := def.Properties[field]
+		if !ok || len(property.AllOf) != 1 {
+			t.Fatalf("%s.%s = %+v, want a nullable allOf reference", name, field, property)
+		}
+	}
+	if got, want := g.Definitions["mutualA"].Properties["B"].AllOf[0].Ref.String(), "#/definitions/mutualB"; got != want {
+		t.Errorf("mutualA.B references %q, want %q", got, want)
+	}
+}
+
+type counter struct {
+	Name  string `json:"name"`
+	mu    sync.Mutex
+	count int
+}
+
+func TestPropertySkipsUnexportedFields(t *testing.T) {
+	g := newTestGenerator()
+	schema := g.Property(reflect.TypeOf(counter{}))
+
+	if got, want := schema.Required, []string{"name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("required = %v, want %v", got, want)
+	}
+	for _, name := range []string{"mu", "count"} {
+		if _, ok := schema.Properties[name]; ok {
+			t.Errorf("unexported field %s documented as a property", name)
+		}
+	}
+}
+
+type inner struct {
+	X int
+}
+
+type outer struct {
+	Inner inner
+}
+
+func TestPropertyReferencesNamedStructFields(t *testing.T) {
+	g := newTestGenerator()
+	g.AddDefinition(reflect.TypeOf(outer{}))
+
+	if _, ok := g.Definitions["inner"]; !ok {
+		t.Fatal("definition inner missing")
+	}
+	property := g.Definitions["outer"].Properties["Inner"]
+	if got, want := property.Ref.String(), "#/definitions/inner"; got != want {
+		t.Errorf("outer.Inner references %q, want %q", got, want)
+	}
+}
+
+type constrained struct {
+	Code  string `openapi:"minLength=2,pattern=^[A-Z]{2,3}$"`
+	Count int    `openapi:"min=0,max=10"`
+}
+
+func TestPropertyConstraints(t *testing.T) {
+	g := newTestGenerator()
+	schema := g.Property(reflect.TypeOf(constrained{}))
+
+	code := schema.Properties["Code"]
+	if got, want := code.Pattern, "^[A-Z]{2,3}$"; got != want {
+		t.Errorf("Code pattern = %q, want %q", got, want)
+	}
+	if code.MinLength == nil || *code.MinLength != 2 {
+		t.Errorf("Code minLength = %v, want 2", code.MinLength)
+	}
+	count := schema.Properties["Count"]
+	if count.Minimum == nil || *count.Minimum != 0 || count.Maximum == nil || *count.Maximum != 10 {
+		t.Errorf("Count minimum, maximum = %v, %v, want 0, 10", count.Minimum, count.Maximum)
+	}
+}
+
+type Meta map[string]string
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type embedding struct {
+	base
+	Meta
+	Name string `json:"name"`
+}
+
+func TestPropertyEmbeddedTypes(t *testing.T) {
+	g := newTestGenerator()
+	schema := g.Property(reflect.TypeOf(embedding{}))
+
+	if !schema.Type.Contains("object") {
+		t.Errorf("type = %v, want object", schema.Type)
+	}
+	if len(schema.AllOf) != 2 {
+		t.Fatalf("allOf = %+v, want the embedded struct and the own fields", schema.AllOf)
+	}
+	if got, want := schema.AllOf[0].Ref.String(), "#/definitions/base"; got != want {
+		t.Errorf("allOf[0] references %q, want %q", got, want)
+	}
+	own := schema.AllOf[1]
+	meta, ok := own.Properties["Meta"]
+	if !ok {
+		t.Fatalf("properties = %v, want Meta documented as a property", own.Properties)
+	}
+	if meta.AdditionalProperties == nil || !meta.AdditionalProperties.Schema.Type.Contains("string") {
+		t.Errorf("Meta = %+v, want a map of strings", meta)
+	}
+	if got, want := own.Required, []string{"Meta", "name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("required = %v, want %v", got, want)
+	}
+}
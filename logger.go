@@ -0,0 +1,22 @@
+package ghttp
+
+import (
+	"log/slog"
+)
+
+var logger *slog.Logger
+
+// SetLogger replaces the logger used by ghttp and its subpackages, which
+// defaults to slog.Default(). It is not safe for concurrent use and should be
+// called during initialization.
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
+// Logger returns the logger set with SetLogger, or slog.Default().
+func Logger() *slog.Logger {
+	if logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/spec"
+)
+
+// paramDocumenter matches chi.MiddlewareParamDocumenter without importing the
+// chi subpackage.
+type paramDocumenter interface {
+	MiddlewareParams() []spec.Parameter
+}
+
+// Chain is a named stack of middlewares. Use Chain.Handler as a chi
+// middleware; the spec generator then lists the chain under its name in the
+// `x-middlewares` extension and documents the parameters of its middlewares.
+type Chain struct {
+	name        string
+	middlewares []func(http.Handler) http.Handler
+}
+
+func NewChain(name string, middlewares ...func(http.Handler) http.Handler) Chain {
+	return Chain{
+		name:        name,
+		middlewares: middlewares,
+	}
+}
+
+func (c Chain) Name() string {
+	return c.name
+}
+
+// Handler wraps next in the middlewares of the chain, the first being the
+// outermost.
+func (c Chain) Handler(next http.Handler) http.Handler {
+	h := next
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return chainHandler{Handler: h, chain: c}
+}
+
+func (c Chain) Then(h http.Handler) http.Handler {
+	return c.Handler(h)
+}
+
+// MiddlewareParams collects the documented parameters of every middleware in
+// the chain.
+func (c Chain) MiddlewareParams() []spec.Parameter {
+	var params []spec.Parameter
+	for _, mw := range c.middlewares {
+		var documenter paramDocumenter
+		documenter, _ = mw(http.NotFoundHandler()).(paramDocumenter)
+		if documenter != nil {
+			params = append(params, documenter.MiddlewareParams()...)
+		}
+	}
+	return params
+}
+
+type chainHandler struct {
+	http.Handler
+	chain Chain
+}
+
+func (h chainHandler) MiddlewareName() string {
+	return h.chain.Name()
+}
+
+func (h chainHandler) MiddlewareParams() []spec.Parameter {
+	return h.chain.MiddlewareParams()
+}
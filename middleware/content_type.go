@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RequireContentType rejects requests whose Content-Type header does not
+// contain ct with a 415 Unsupported Media Type.
+func RequireContentType(ct string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.Contains(r.Header.Get("Content-Type"), ct) {
+				writeJSONError(w, http.StatusUnsupportedMediaType, "expected Content-Type: "+ct)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
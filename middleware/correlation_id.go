@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+type correlationIDKey struct{}
+
+type correlationID struct {
+	header string
+	id     string
+}
+
+// CorrelationID reads the correlation ID from the headerName request header,
+// generating a random UUID v4 when it is missing. The ID is stored in the
+// request context and echoed in the same response header.
+func CorrelationID(headerName string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(headerName)
+			if id == "" {
+				id = newUUID()
+			}
+			w.Header().Set(headerName, id)
+			ctx := context.WithValue(r.Context(), correlationIDKey{}, correlationID{header: headerName, id: id})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// CorrelationIDFromContext returns the ID stored by CorrelationID, or "" if
+// the middleware was not used.
+func CorrelationIDFromContext(ctx context.Context) string {
+	cid, _ := ctx.Value(correlationIDKey{}).(correlationID)
+	return cid.id
+}
+
+// CorrelationIDHeaderFromContext returns the header name CorrelationID read
+// the ID from, or "" if the middleware was not used.
+func CorrelationIDHeaderFromContext(ctx context.Context) string {
+	cid, _ := ctx.Value(correlationIDKey{}).(correlationID)
+	return cid.header
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
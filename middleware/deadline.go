@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DeadlinePropagation logs a warning for requests whose context deadline was
+// exceeded while they were served, and sets `X-Deadline-Exceeded: true` on
+// the response if it had not been committed yet.
+func DeadlinePropagation() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			dw := &deadlineWriter{ResponseWriter: w, ctx: r.Context()}
+			next.ServeHTTP(dw, r)
+			if !errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+				return
+			}
+			if !dw.committed {
+				w.Header().Set("X-Deadline-Exceeded", "true")
+			}
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			deadline, _ := r.Context().Deadline()
+			ghttp.Logger().Warn("request deadline exceeded",
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.Duration("deadline", deadline.Sub(start)),
+				slog.Duration("duration", time.Since(start)),
+			)
+		})
+	}
+}
+
+// deadlineWriter sets the deadline header when the response is committed
+// after the deadline has passed.
+type deadlineWriter struct {
+	http.ResponseWriter
+	ctx       context.Context
+	committed bool
+}
+
+func (w *deadlineWriter) WriteHeader(statusCode int) {
+	if !w.committed {
+		w.committed = true
+		if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+			w.Header().Set("X-Deadline-Exceeded", "true")
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *deadlineWriter) Write(b []byte) (int, error) {
+	if !w.committed {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *deadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush commits the response, as a write would, before flushing it so
+// streaming handlers keep working under the middleware.
+func (w *deadlineWriter) Flush() {
+	if !w.committed {
+		w.WriteHeader(http.StatusOK)
+	}
+	if err := http.NewResponseController(w.ResponseWriter).Flush(); err != nil {
+		return
+	}
+}
+
+func (w *deadlineWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
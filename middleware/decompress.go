@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecompressRequest transparently decompresses request bodies sent with a
+// gzip or deflate Content-Encoding and removes the header, so downstream
+// handlers read plain bodies. Bodies that cannot be decompressed are rejected
+// with a 400 and other encodings, including br, with a 415.
+//
+// Reading more than maxSize decompressed bytes fails with an
+// *http.MaxBytesError, which ghttp handlers answer with a 413, so a small
+// compressed body cannot expand without bound. A maxSize of zero or less
+// leaves the decompressed body unlimited.
+func DecompressRequest(maxSize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			var body io.ReadCloser
+			var err error
+			switch encoding {
+			case "", "identity":
+				next.ServeHTTP(w, r)
+				return
+			case "gzip", "x-gzip":
+				body, err = gzip.NewReader(r.Body)
+			case "deflate":
+				body, err = zlib.NewReader(r.Body)
+			default:
+				writeJSONError(w, http.StatusUnsupportedMediaType, "unsupported Content-Encoding: "+encoding)
+				return
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "invalid "+encoding+" body")
+				return
+			}
+			defer body.Close()
+			r.Body = body
+			if maxSize > 0 {
+				r.Body = http.MaxBytesReader(w, body, maxSize)
+			}
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+			next.ServeHTTP(w, r)
+		})
+	}
+}
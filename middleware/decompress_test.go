@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecompressRequestMaxSize(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(strings.Repeat("a", 1024)))
+	zw.Close()
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		wantErr bool
+	}{
+		{"unlimited", 0, false},
+		{"within limit", 1024, false},
+		{"over limit", 1023, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n int
+			var err error
+			h := DecompressRequest(tt.maxSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body []byte
+				body, err = io.ReadAll(r.Body)
+				n = len(body)
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(compressed.Bytes()))
+			req.Header.Set("Content-Encoding", "gzip")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			var maxBytesErr *http.MaxBytesError
+			if gotErr := errors.As(err, &maxBytesErr); gotErr != tt.wantErr {
+				t.Errorf("read %d bytes with error %v, want MaxBytesError: %t", n, err, tt.wantErr)
+			}
+		})
+	}
+}
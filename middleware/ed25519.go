@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Ed25519Verify rejects the request with a 401 unless the base64-encoded
+// signature in headerName is a valid Ed25519 signature of the request body
+// by pubKey. The body is read in full and restored for the next handler;
+// bodies larger than maxBodySize bytes are rejected with a 413 before being
+// buffered.
+func Ed25519Verify(pubKey ed25519.PublicKey, headerName string, maxBodySize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sig, err := base64.StdEncoding.DecodeString(r.Header.Get(headerName))
+			if err != nil || len(sig) != ed25519.SignatureSize {
+				writeJSONError(w, http.StatusUnauthorized, "invalid signature")
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "reading request body")
+				return
+			}
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if !ed25519.Verify(pubKey, body, sig) {
+				writeJSONError(w, http.StatusUnauthorized, "invalid signature")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEd25519Verify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(body string) string {
+		return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(body)))
+	}
+	tests := []struct {
+		name string
+		body string
+		sig  string
+		want int
+	}{
+		{"valid", `{"a":1}`, sign(`{"a":1}`), http.StatusOK},
+		{"wrong signature", `{"a":1}`, sign(`{"a":2}`), http.StatusUnauthorized},
+		{"too large", `{"a":12345}`, sign(`{"a":12345}`), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Ed25519Verify(pub, "X-Signature", 8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("X-Signature", tt.sig)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"ghttp"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSONError(w http.ResponseWriter, statusCode int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(errorResponse{Error: msg}); err != nil {
+		ghttp.Logger().Error("encoding error body", slog.Any("error", err))
+		return
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type handlerNameKey struct{}
+
+// HandlerName stores the name of the wrapped handler in the request context
+// and in the `X-Handler` response header. Function handlers are named after
+// their function, other handlers after their type. When installed with a chi
+// router's Use, it runs before the request is routed, so the handler the
+// request is routed to is looked up instead.
+func HandlerName() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		name := handlerName(next)
+		var routed routedNames
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := name
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.Routes != nil && !routedToEndpoint(rctx) {
+				name = routed.lookup(rctx.Routes, r)
+			}
+			w.Header().Set("X-Handler", name)
+			ctx := context.WithValue(r.Context(), handlerNameKey{}, name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// HandlerNameFromContext returns the name stored by HandlerName, or "" if the
+// middleware was not used.
+func HandlerNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(handlerNameKey{}).(string)
+	return name
+}
+
+func handlerName(h http.Handler) string {
+	v := reflect.ValueOf(h)
+	if v.Kind() == reflect.Func {
+		name := runtime.FuncForPC(v.Pointer()).Name()
+		return name[strings.LastIndex(name, "/")+1:]
+	}
+	return reflect.TypeOf(h).String()
+}
+
+// routedToEndpoint reports whether chi has already routed the request to its
+// endpoint, as it has for middlewares added with With, rather than to a
+// mounted subrouter or not at all, as for middlewares added with Use.
+func routedToEndpoint(rctx *chi.Context) bool {
+	n := len(rctx.RoutePatterns)
+	return n > 0 && !strings.HasSuffix(rctx.RoutePatterns[n-1], "/*")
+}
+
+// routedNames names the handlers of a chi router by method and route
+// pattern, walking it on first use.
+type routedNames struct {
+	once  sync.Once
+	names map[string]string
+}
+
+// lookup returns the name of the handler routes routes r to, or "" if there
+// is none.
+func (rn *routedNames) lookup(routes chi.Routes, r *http.Request) string {
+	rn.once.Do(func() {
+		rn.names = map[string]string{}
+		chi.Walk(routes, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			rn.names[method+" "+route] = handlerName(handler)
+			return nil
+		})
+	})
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	rctx := chi.NewRouteContext()
+	if !routes.Match(rctx, r.Method, path) {
+		return ""
+	}
+	return rn.names[r.Method+" "+rctx.RoutePattern()]
+}
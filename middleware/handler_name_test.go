@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func getUser(w http.ResponseWriter, r *http.Request) {}
+
+func listOrders(w http.ResponseWriter, r *http.Request) {}
+
+func TestHandlerName(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(HandlerName())
+	r.Get("/users/{id}", getUser)
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/orders", listOrders)
+	})
+	withRouter := chi.NewRouter()
+	withRouter.With(HandlerName()).Get("/users/{id}", getUser)
+	withRouter.Route("/api", func(r chi.Router) {
+		r.With(HandlerName()).Get("/orders", listOrders)
+	})
+	wrapped := HandlerName()(http.HandlerFunc(getUser))
+
+	tests := []struct {
+		name   string
+		router http.Handler
+		path   string
+		want   string
+	}{
+		{"use", r, "/users/1", "middleware.getUser"},
+		{"use subrouter", r, "/api/orders", "middleware.listOrders"},
+		{"use not found", r, "/missing", ""},
+		{"with", withRouter, "/users/1", "middleware.getUser"},
+		{"with subrouter", withRouter, "/api/orders", "middleware.listOrders"},
+		{"without router", wrapped, "/", "middleware.getUser"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if got := rec.Header().Get("X-Handler"); got != tt.want {
+				t.Errorf("X-Handler = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const rateLimitSweepInterval = time.Minute
+
+type RateLimitOption func(*rateLimiter)
+
+// WithKeyFunc keys rate limits by the value fn returns instead of the client
+// IP, e.g. the subject claim of an authenticated user. An empty key falls back
+// to the client IP.
+func WithKeyFunc(fn func(*http.Request) string) RateLimitOption {
+	return func(l *rateLimiter) {
+		l.keyFn = fn
+	}
+}
+
+// WithTierFn sets the allowed requests per second per request, e.g. from the
+// plan of the authenticated user, overriding the default rate.
+func WithTierFn(fn func(*http.Request) float64) RateLimitOption {
+	return func(l *rateLimiter) {
+		l.tierFn = fn
+	}
+}
+
+// RateLimit allows rps requests per second per key, with bursts of up to
+// burst requests, and rejects the rest with a 429. Keys default to the client
+// IP.
+func RateLimit(rps float64, burst int, opts ...RateLimitOption) func(http.Handler) http.Handler {
+	l := &rateLimiter{
+		rps:       rps,
+		burst:     float64(burst),
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rate := l.rps
+			if l.tierFn != nil {
+				rate = l.tierFn(r)
+			}
+			if wait := l.take(l.key(r), rate, time.Now()); wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// rate is the refill rate of the last request, used by sweep to tell
+	// when the bucket is full again.
+	rate float64
+}
+
+type rateLimiter struct {
+	rps    float64
+	burst  float64
+	keyFn  func(*http.Request) string
+	tierFn func(*http.Request) float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func (l *rateLimiter) key(r *http.Request) string {
+	if l.keyFn != nil {
+		if key := l.keyFn(r); key != "" {
+			return key
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// take consumes a token from the bucket of key, refilled at rate tokens per
+// second. It returns how long to wait for the next token when none is left.
+func (l *rateLimiter) take(key string, rate float64, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) > rateLimitSweepInterval {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.rate = rate
+	b.tokens = b.refilled(l.burst, now)
+	b.last = now
+	if b.tokens < 1 {
+		if rate <= 0 {
+			return rateLimitSweepInterval
+		}
+		return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// refilled returns the tokens in b at now, up to burst.
+func (b *bucket) refilled(burst float64, now time.Time) float64 {
+	return math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+}
+
+// sweep drops buckets that have refilled to burst, which are no different
+// from the new bucket the next request would get. Buckets still refilling
+// are kept, however long they have been idle, so a slow rate cannot be
+// bypassed by waiting for a sweep.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.refilled(l.burst, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSweepKeepsRefillingBuckets(t *testing.T) {
+	start := time.Now()
+	l := &rateLimiter{burst: 2, buckets: map[string]*bucket{}, lastSweep: start}
+
+	// One token a minute: two minutes after draining, one token is back.
+	const rate = 1.0 / 60
+	l.take("slow", rate, start)
+	l.take("slow", rate, start)
+	// Ten tokens a second: full again well before the sweep.
+	l.take("fast", 10, start)
+
+	now := start.Add(2*rateLimitSweepInterval - time.Second)
+	l.sweep(now)
+	if _, ok := l.buckets["fast"]; ok {
+		t.Error("full bucket kept")
+	}
+	if _, ok := l.buckets["slow"]; !ok {
+		t.Fatal("refilling bucket dropped")
+	}
+	l.take("slow", rate, now)
+	if wait := l.take("slow", rate, now); wait <= 0 {
+		t.Errorf("second request after the sweep allowed, want it limited")
+	}
+}
@@ -0,0 +1,50 @@
+package ghttp
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// MultiResponseTyper is implemented by handlers that respond with a different
+// type per status code. It takes precedence over ResponseTyper.
+type MultiResponseTyper interface {
+	ResponseTypes() map[int]reflect.Type
+}
+
+// JSONMultiResponseHandlerFunc returns both a success and an error response;
+// only the one matching the status code is sent.
+type JSONMultiResponseHandlerFunc[O200 any, O4xx any] func(http.ResponseWriter, *http.Request) (O200, O4xx, int)
+
+// JSONMultiResponseHandler encodes `O200` for 2xx status codes and `O4xx` for
+// any other, documenting them as the 200 and 400 responses.
+type JSONMultiResponseHandler[O200 any, O4xx any] struct {
+	handlerFn JSONMultiResponseHandlerFunc[O200, O4xx]
+	opts      handlerOptions
+}
+
+func NewMultiResponseHandler[O200 any, O4xx any](fn JSONMultiResponseHandlerFunc[O200, O4xx], opts ...HandlerOption) JSONMultiResponseHandler[O200, O4xx] {
+	return JSONMultiResponseHandler[O200, O4xx]{
+		handlerFn: fn,
+		opts:      newHandlerOptions(opts...),
+	}
+}
+
+func (h JSONMultiResponseHandler[O200, O4xx]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONMultiResponseHandler[O200, O4xx]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	ok, failed, statusCode := h.handlerFn(w, r)
+	if statusCode >= 200 && statusCode < 300 {
+		h.opts.writeJSON(w, statusCode, ok)
+		return
+	}
+	h.opts.writeJSON(w, statusCode, failed)
+}
+
+func (h JSONMultiResponseHandler[O200, O4xx]) ResponseTypes() map[int]reflect.Type {
+	return map[int]reflect.Type{
+		http.StatusOK:         reflect.TypeOf((*O200)(nil)).Elem(),
+		http.StatusBadRequest: reflect.TypeOf((*O4xx)(nil)).Elem(),
+	}
+}
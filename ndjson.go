@@ -0,0 +1,87 @@
+package ghttp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+type NDJSONPayloadHandlerFunc[I any, O any] func(http.ResponseWriter, *http.Request, []I) (O, int)
+
+// NDJSONPayloadHandler decodes a newline-delimited JSON request body, one `I`
+// per line, and passes the decoded slice to its handler function.
+type NDJSONPayloadHandler[I any, O any] struct {
+	handlerFunc NDJSONPayloadHandlerFunc[I, O]
+	opts        handlerOptions
+}
+
+func NewNDJSONPayloadHandler[I any, O any](fn NDJSONPayloadHandlerFunc[I, O], opts ...HandlerOption) NDJSONPayloadHandler[I, O] {
+	return NDJSONPayloadHandler[I, O]{
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+}
+
+func (h NDJSONPayloadHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h NDJSONPayloadHandler[I, O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload []I
+	err := h.opts.readBody(w, r, func(body io.Reader) error {
+		var err error
+		payload, err = decodeNDJSON[I](body)
+		return err
+	})
+	if err != nil {
+		h.opts.writeDecodeError(w, err)
+		return
+	}
+	resp, statusCode := h.handlerFunc(w, r, payload)
+	h.opts.writeJSON(w, statusCode, resp)
+}
+
+// decodeNDJSON decodes every non-blank line of body as an `I`. Invalid lines
+// do not stop decoding; their errors are joined and returned together.
+func decodeNDJSON[I any](body io.Reader) ([]I, error) {
+	var payload []I
+	var errs []error
+	br := bufio.NewReader(body)
+	for lineNum := 1; ; lineNum++ {
+		line, readErr := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var v I
+			if err := json.Unmarshal(line, &v); err != nil {
+				errs = append(errs, fmt.Errorf("line %d: %w", lineNum, err))
+			} else {
+				payload = append(payload, v)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNum, readErr))
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return payload, nil
+}
+
+func (h NDJSONPayloadHandler[I, O]) PayloadType() reflect.Type {
+	var v []I
+	return reflect.TypeOf(v)
+}
+
+func (h NDJSONPayloadHandler[I, O]) ResponseType() reflect.Type {
+	var v O
+	return reflect.TypeOf(v)
+}
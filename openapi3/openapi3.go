@@ -0,0 +1,329 @@
+// Package openapi3 documents the ghttp handlers of a chi router as an
+// OpenAPI 3.0.1 document. It is the OpenAPI 3 counterpart of the Swagger 2.0
+// document served by package ghttp/chi.
+package openapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ghttp"
+	"ghttp/internal/typeschema"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-openapi/spec"
+)
+
+const (
+	Version       = "3.0.1"
+	schemasPrefix = "#/components/schemas/"
+)
+
+var (
+	pathParamPattern = regexp.MustCompile("{([^}]+)}")
+	emptyStructType  = reflect.TypeOf(struct{}{})
+)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path, keyed by lowercase method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// Security lists the requirements of a ghttp.SecurityRequirer, any one
+	// of which is sufficient.
+	Security []map[string][]string `json:"security,omitempty"`
+	// Streaming marks operations responding with a stream of server-sent
+	// events, whose response schema documents each event's data.
+	Streaming bool `json:"x-streaming,omitempty"`
+}
+
+type Parameter struct {
+	Name     string       `json:"name"`
+	In       string       `json:"in"`
+	Required bool         `json:"required,omitempty"`
+	Schema   *spec.Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *spec.Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         spec.Definitions          `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is an OpenAPI 3 security scheme. Type is "apiKey", "http",
+// "oauth2" or "openIdConnect", and decides which other fields apply.
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	// Name and In locate an apiKey: In is "header", "query" or "cookie".
+	Name string `json:"name,omitempty"`
+	In   string `json:"in,omitempty"`
+	// Scheme is the HTTP authentication scheme, e.g. "bearer" or "basic".
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	// Flows configures an oauth2 scheme.
+	Flows            *OAuthFlows `json:"flows,omitempty"`
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+// OAuthFlow describes an OAuth 2 flow. Scopes maps each scope to its
+// description.
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// Config controls how the OpenAPI 3 document is generated.
+type Config struct {
+	// Info becomes the document's `info` block.
+	Info Info
+	// SecuritySchemes become the document's `components.securitySchemes`.
+	// Handlers reference them by name through ghttp.SecurityRequirer.
+	SecuritySchemes map[string]SecurityScheme
+}
+
+type SpecOption func(*Config)
+
+func newConfig(opts ...SpecOption) Config {
+	var cfg Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+func WithTitle(s string) SpecOption {
+	return func(cfg *Config) {
+		cfg.Info.Title = s
+	}
+}
+
+func WithVersion(s string) SpecOption {
+	return func(cfg *Config) {
+		cfg.Info.Version = s
+	}
+}
+
+// WithSecurityScheme adds scheme to the document's
+// `components.securitySchemes` as name.
+func WithSecurityScheme(name string, scheme SecurityScheme) SpecOption {
+	return func(cfg *Config) {
+		if cfg.SecuritySchemes == nil {
+			cfg.SecuritySchemes = map[string]SecurityScheme{}
+		}
+		cfg.SecuritySchemes[name] = scheme
+	}
+}
+
+// WithBearerSecurity documents an HTTP bearer token scheme called name.
+// bearerFormat hints at the token format, e.g. "JWT", and may be empty.
+func WithBearerSecurity(name string, bearerFormat string) SpecOption {
+	return WithSecurityScheme(name, SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: bearerFormat})
+}
+
+// WithBasicSecurity documents an HTTP basic authentication scheme called
+// name.
+func WithBasicSecurity(name string) SpecOption {
+	return WithSecurityScheme(name, SecurityScheme{Type: "http", Scheme: "basic"})
+}
+
+// WithAPIKeySecurity documents an apiKey security scheme called name, sent as
+// paramName in the given location: "header", "query" or "cookie". It panics
+// for any other location.
+func WithAPIKeySecurity(name string, paramName string, in string) SpecOption {
+	if in != "header" && in != "query" && in != "cookie" {
+		panic(fmt.Sprintf("openapi3: API key location %q is not \"header\", \"query\" or \"cookie\"", in))
+	}
+	return WithSecurityScheme(name, SecurityScheme{Type: "apiKey", Name: paramName, In: in})
+}
+
+// HandlerFunc serves the OpenAPI 3 document of r as JSON. The document is
+// built on the first request.
+func HandlerFunc(r chi.Router, opts ...SpecOption) http.HandlerFunc {
+	docFn := sync.OnceValue(func() Document {
+		return NewDocument(r, opts...)
+	})
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(docFn()); err != nil {
+			ghttp.Logger().Error("encoding doc", slog.Any("error", err))
+			return
+		}
+	}
+}
+
+// NewDocument generates the OpenAPI 3 document for r.
+func NewDocument(r chi.Router, opts ...SpecOption) Document {
+	cfg := newConfig(opts...)
+	doc := Document{
+		OpenAPI: Version,
+		Info:    cfg.Info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas:         spec.Definitions{},
+			SecuritySchemes: cfg.SecuritySchemes,
+		},
+	}
+	gen := typeschema.Generator{
+		Definitions: doc.Components.Schemas,
+		RefPrefix:   schemasPrefix,
+	}
+	chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = stripParamPatterns(route)
+		operation := &Operation{
+			Responses: map[string]Response{
+				"default": defaultErrorResponse(),
+			},
+		}
+
+		var pTyper ghttp.PayloadTyper
+		pTyper, _ = handler.(ghttp.PayloadTyper)
+		if pTyper != nil {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(gen.TypeRef(pTyper.PayloadType())),
+			}
+		}
+
+		produces := []string{"application/json"}
+		var producer ghttp.Producer
+		producer, _ = handler.(ghttp.Producer)
+		if producer != nil {
+			produces = producer.Produces()
+			operation.Streaming = slices.Contains(produces, "text/event-stream")
+		}
+
+		var rTyper ghttp.ResponseTyper
+		rTyper, _ = handler.(ghttp.ResponseTyper)
+		if rTyper != nil {
+			rt := rTyper.ResponseType()
+			if rt == emptyStructType {
+				operation.Responses[strconv.Itoa(http.StatusNoContent)] = Response{Description: http.StatusText(http.StatusNoContent)}
+			} else {
+				operation.Responses[strconv.Itoa(http.StatusOK)] = Response{
+					Description: http.StatusText(http.StatusOK),
+					Content:     content(gen.TypeRef(rt), produces...),
+				}
+			}
+		}
+
+		var sRequirer ghttp.SecurityRequirer
+		sRequirer, _ = handler.(ghttp.SecurityRequirer)
+		if sRequirer != nil {
+			operation.Security = sRequirer.SecurityRequirements()
+		}
+
+		pathParams := pathParamPattern.FindAllStringSubmatch(route, -1)
+		for _, pathParam := range pathParams {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:     pathParam[1],
+				In:       "path",
+				Required: true,
+				Schema:   spec.StringProperty(),
+			})
+		}
+
+		pathItem, ok := doc.Paths[route]
+		if !ok {
+			pathItem = PathItem{}
+			doc.Paths[route] = pathItem
+		}
+		pathItem[strings.ToLower(method)] = operation
+		return nil
+	})
+	return doc
+}
+
+// stripParamPatterns removes the regular expressions of chi path parameters
+// from route, e.g. "/users/{id:[0-9]+}" becomes "/users/{id}". Like chi, it
+// allows braces in the expressions, as in "{code:[a-z]{2}}".
+func stripParamPatterns(route string) string {
+	var b strings.Builder
+	depth := 0
+	inPattern := false
+	for _, r := range route {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+			if depth == 0 {
+				inPattern = false
+			}
+		case r == ':' && depth == 1:
+			inPattern = true
+		}
+		if !inPattern {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// defaultErrorResponse is the `default` response of every operation,
+// documenting the `{"error": "..."}` body of ghttp's error responses.
+func defaultErrorResponse() Response {
+	schema := new(spec.Schema).
+		Typed("object", "").
+		SetProperty("error", *spec.StringProperty())
+	return Response{Description: "Error", Content: jsonContent(schema)}
+}
+
+func jsonContent(schema *spec.Schema) map[string]MediaType {
+	return content(schema, "application/json")
+}
+
+// content documents schema as the body of each of contentTypes.
+func content(schema *spec.Schema, contentTypes ...string) map[string]MediaType {
+	c := make(map[string]MediaType, len(contentTypes))
+	for _, contentType := range contentTypes {
+		c[contentType] = MediaType{Schema: schema}
+	}
+	return c
+}
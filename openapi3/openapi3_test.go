@@ -0,0 +1,78 @@
+package openapi3
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"ghttp"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func respond[O any](opts ...ghttp.HandlerOption) ghttp.JSONHandler[O] {
+	return ghttp.NewJSONHandler(func(w http.ResponseWriter, r *http.Request) (O, http.Header, int) {
+		var v O
+		return v, nil, http.StatusOK
+	}, opts...)
+}
+
+func TestNewDocumentSecurity(t *testing.T) {
+	r := chi.NewRouter()
+	r.Method(http.MethodGet, "/private", respond[string](ghttp.WithSecurityRequirements(map[string][]string{"bearer": nil}, map[string][]string{"session": {}})))
+	r.Method(http.MethodGet, "/public", respond[string]())
+
+	doc := NewDocument(r,
+		WithBearerSecurity("bearer", "JWT"),
+		WithAPIKeySecurity("session", "session_id", "cookie"),
+		WithSecurityScheme("oauth", SecurityScheme{Type: "oauth2", Flows: &OAuthFlows{
+			ClientCredentials: &OAuthFlow{TokenURL: "https://example.com/token", Scopes: map[string]string{"read": "Read access"}},
+		}}),
+	)
+
+	want := map[string]SecurityScheme{
+		"bearer":  {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		"session": {Type: "apiKey", Name: "session_id", In: "cookie"},
+		"oauth": {Type: "oauth2", Flows: &OAuthFlows{
+			ClientCredentials: &OAuthFlow{TokenURL: "https://example.com/token", Scopes: map[string]string{"read": "Read access"}},
+		}},
+	}
+	if got := doc.Components.SecuritySchemes; !reflect.DeepEqual(got, want) {
+		t.Errorf("securitySchemes = %+v, want %+v", got, want)
+	}
+	wantSecurity := []map[string][]string{{"bearer": nil}, {"session": {}}}
+	if got := doc.Paths["/private"]["get"].Security; !reflect.DeepEqual(got, wantSecurity) {
+		t.Errorf("/private security = %v, want %v", got, wantSecurity)
+	}
+	if got := doc.Paths["/public"]["get"].Security; got != nil {
+		t.Errorf("/public security = %v, want none", got)
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	r := chi.NewRouter()
+	r.Method(http.MethodGet, "/users/{id:[0-9]+}/codes/{code:[a-z]{2}}", respond[string]())
+	r.Method(http.MethodPost, "/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	doc := NewDocument(r, WithTitle("Users"), WithVersion("1.2.0"))
+
+	if want := (Info{Title: "Users", Version: "1.2.0"}); doc.Info != want {
+		t.Errorf("info = %+v, want %+v", doc.Info, want)
+	}
+	item, ok := doc.Paths["/users/{id}/codes/{code}"]
+	if !ok {
+		t.Fatalf("paths = %v, want the route without its parameter patterns", reflect.ValueOf(doc.Paths).MapKeys())
+	}
+	var names []string
+	for _, parameter := range item["get"].Parameters {
+		names = append(names, parameter.Name)
+	}
+	if want := []string{"id", "code"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("parameters = %v, want %v", names, want)
+	}
+	for route, method := range map[string]string{"/users/{id}/codes/{code}": "get", "/ping": "post"} {
+		if _, ok := doc.Paths[route][method].Responses["default"]; !ok {
+			t.Errorf("%s %s has no default response", method, route)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package ghttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"time"
+)
+
+var (
+	errBodyReadTimeout = errors.New("request body read timed out")
+	errBodyTooLarge    = errors.New("request body too large")
+)
+
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	preprocess  []func(context.Context, interface{}) error
+	postprocess []func(context.Context, interface{}, int) error
+	// preprocessTypes and postprocessTypes hold the type parameters of the
+	// preprocess and postprocess options, checked by checkProcessTypes.
+	preprocessTypes  []reflect.Type
+	postprocessTypes []reflect.Type
+
+	pushResources []PushResource
+
+	jsonIndent       string
+	jsonNoEscapeHTML bool
+
+	queryParamType reflect.Type
+	pathParamType  reflect.Type
+
+	successStatus int
+
+	responseHeaders map[int]map[string]reflect.Type
+
+	webhookSignatureHeader string
+
+	sdkClientName   string
+	sdkClientMethod string
+
+	requireContentType bool
+
+	bodyReadTimeout time.Duration
+	maxBodySize     int64
+
+	defaultQueryParams url.Values
+
+	timeout time.Duration
+
+	fallback *fallbackResponse
+
+	securityRequirements []map[string][]string
+
+	sunset time.Time
+
+	batchConcurrency int
+}
+
+type fallbackResponse struct {
+	statusCode int
+	body       []byte
+}
+
+func newHandlerOptions(opts ...HandlerOption) handlerOptions {
+	var o handlerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithPreprocess runs fn after the payload is decoded and before the handler
+// function is called. Returning an error aborts the request with a 400.
+// I must match the payload type of the handler the option is passed to, or
+// the handler's constructor panics.
+func WithPreprocess[I any](fn func(context.Context, *I) error) HandlerOption {
+	return func(o *handlerOptions) {
+		o.preprocess = append(o.preprocess, func(ctx context.Context, payload interface{}) error {
+			return fn(ctx, payload.(*I))
+		})
+		o.preprocessTypes = append(o.preprocessTypes, reflect.TypeOf((*I)(nil)).Elem())
+	}
+}
+
+// WithPostprocess runs fn after the handler function returns and before the
+// response is encoded, allowing it to mutate the response. Returning an error
+// aborts the request with a 500.
+// O must match the response type of the handler the option is passed to, or
+// the handler's constructor panics.
+func WithPostprocess[O any](fn func(context.Context, *O, int) error) HandlerOption {
+	return func(o *handlerOptions) {
+		o.postprocess = append(o.postprocess, func(ctx context.Context, resp interface{}, statusCode int) error {
+			return fn(ctx, resp.(*O), statusCode)
+		})
+		o.postprocessTypes = append(o.postprocessTypes, reflect.TypeOf((*O)(nil)).Elem())
+	}
+}
+
+// checkProcessTypes panics if a preprocess or postprocess option was given
+// for a different payload or response type than the handler's, so the
+// mismatch is found when the handler is built rather than on every request.
+func (o handlerOptions) checkProcessTypes(payloadType reflect.Type, responseType reflect.Type) {
+	for _, t := range o.preprocessTypes {
+		if t != payloadType {
+			panic(fmt.Sprintf("ghttp: WithPreprocess for %s passed to a handler with payload type %s", t, payloadType))
+		}
+	}
+	for _, t := range o.postprocessTypes {
+		if t != responseType {
+			panic(fmt.Sprintf("ghttp: WithPostprocess for %s passed to a handler with response type %s", t, responseType))
+		}
+	}
+}
+
+// WithPushResources declares resources to push to HTTP/2 clients before the
+// response is written.
+func WithPushResources(resources ...PushResource) HandlerOption {
+	return func(o *handlerOptions) {
+		o.pushResources = append(o.pushResources, resources...)
+	}
+}
+
+// WithJSONOptions configures the response encoder. indent is applied per
+// nesting level, except by handlers writing one value per line, and
+// escapeHTML false stops `<`, `>` and `&` from being escaped in strings.
+func WithJSONOptions(indent string, escapeHTML bool) HandlerOption {
+	return func(o *handlerOptions) {
+		o.jsonIndent = indent
+		o.jsonNoEscapeHTML = !escapeHTML
+	}
+}
+
+// WithQueryParams documents the fields of struct T as the query parameters of
+// the handler. Read them with QueryBind[T].
+func WithQueryParams[T any]() HandlerOption {
+	return func(o *handlerOptions) {
+		o.queryParamType = reflect.TypeOf((*T)(nil)).Elem()
+	}
+}
+
+// WithDefaultQueryParams adds params to the query string of requests that do
+// not set them, e.g. to default `page=1&per_page=20`. The merged query is
+// what the handler function and QueryBind see.
+func WithDefaultQueryParams(params url.Values) HandlerOption {
+	return func(o *handlerOptions) {
+		o.defaultQueryParams = params
+	}
+}
+
+// withDefaultQuery returns r with the default query parameters merged into
+// its URL, leaving r itself unchanged.
+func (o handlerOptions) withDefaultQuery(r *http.Request) *http.Request {
+	if len(o.defaultQueryParams) == 0 {
+		return r
+	}
+	query := r.URL.Query()
+	for key, values := range o.defaultQueryParams {
+		if !query.Has(key) {
+			query[key] = values
+		}
+	}
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	r2 := *r
+	r2.URL = &u
+	return &r2
+}
+
+// WithTimeout cancels the request context after d, and documents d as the
+// operation's `x-timeout-ms`.
+func WithTimeout(d time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.timeout = d
+	}
+}
+
+func (o handlerOptions) withTimeout(r *http.Request) (*http.Request, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return r, func() {}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), o.timeout)
+	return r.WithContext(ctx), cancel
+}
+
+// WithFallbackResponse recovers from panics in the handler function and
+// responds with body encoded as JSON and statusCode, e.g. a last-known-good
+// response for a read endpoint. The panic and its stack trace are still
+// logged. body is encoded once, and WithFallbackResponse panics if it cannot
+// be.
+func WithFallbackResponse(statusCode int, body interface{}) HandlerOption {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		panic(fmt.Sprintf("ghttp: encoding fallback response: %v", err))
+	}
+	return func(o *handlerOptions) {
+		o.fallback = &fallbackResponse{statusCode: statusCode, body: append(encoded, '\n')}
+	}
+}
+
+// recoverWithFallback writes the fallback response when the handler panics.
+// Without WithFallbackResponse the panic is left to propagate.
+func (o handlerOptions) recoverWithFallback(w http.ResponseWriter) {
+	if o.fallback == nil {
+		return
+	}
+	if rec := recover(); rec != nil {
+		Logger().Error("handler panic", slog.Any("panic", rec), slog.String("stack", string(debug.Stack())))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(o.fallback.statusCode)
+		if _, err := w.Write(o.fallback.body); err != nil {
+			Logger().Error("writing fallback response", slog.Any("error", err))
+		}
+	}
+}
+
+// serve calls fn with the plumbing every handler shares: it recovers with the
+// fallback response, merges the default query parameters into r and applies
+// the timeout to its context.
+func (o handlerOptions) serve(w http.ResponseWriter, r *http.Request, fn func(http.ResponseWriter, *http.Request)) {
+	defer o.recoverWithFallback(w)
+	r = o.withDefaultQuery(r)
+	r, cancel := o.withTimeout(r)
+	defer cancel()
+	if !o.sunset.IsZero() {
+		w.Header().Set("Sunset", o.sunset.UTC().Format(http.TimeFormat))
+	}
+	fn(w, r)
+}
+
+// WithSunset announces that the operation will stop responding at t, sending
+// it in the Sunset header (RFC 8594) of every response and documenting it as
+// the operation's `x-sunset`.
+func WithSunset(t time.Time) HandlerOption {
+	return func(o *handlerOptions) {
+		o.sunset = t
+	}
+}
+
+// WithSecurityRequirements documents the security schemes the handler
+// accepts, any one of requirements being sufficient, e.g.
+// `WithSecurityRequirements(map[string][]string{"bearer": nil})`.
+func WithSecurityRequirements(requirements ...map[string][]string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.securityRequirements = append(o.securityRequirements, requirements...)
+	}
+}
+
+// WithBatchConcurrency limits a JSONBatchHandler to processing n items at a
+// time. It defaults to runtime.NumCPU().
+func WithBatchConcurrency(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.batchConcurrency = n
+	}
+}
+
+// WithPathParams documents the fields of struct T as the path parameters of
+// the handler.
+func WithPathParams[T any]() HandlerOption {
+	return func(o *handlerOptions) {
+		o.pathParamType = reflect.TypeOf((*T)(nil)).Elem()
+	}
+}
+
+// WithSuccessStatus replaces any 2xx status code returned by the handler
+// function with code, e.g. to make every POST respond with 201. Other status
+// codes are left untouched.
+func WithSuccessStatus(code int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.successStatus = code
+	}
+}
+
+// WithResponseHeaders documents headers, keyed by name, that the handler
+// sends with responses of the given status code, e.g. Location for a 201.
+func WithResponseHeaders(statusCode int, headers map[string]reflect.Type) HandlerOption {
+	return func(o *handlerOptions) {
+		if o.responseHeaders == nil {
+			o.responseHeaders = map[int]map[string]reflect.Type{}
+		}
+		if o.responseHeaders[statusCode] == nil {
+			o.responseHeaders[statusCode] = map[string]reflect.Type{}
+		}
+		for name, t := range headers {
+			o.responseHeaders[statusCode][name] = t
+		}
+	}
+}
+
+// WithWebhookSignature documents that requests to the handler are signed
+// with HMAC-SHA256, with the signature sent in header.
+func WithWebhookSignature(header string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.webhookSignatureHeader = header
+	}
+}
+
+// WithSDKHint names the client and method generated SDKs should expose the
+// handler as.
+func WithSDKHint(clientName string, method string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.sdkClientName = clientName
+		o.sdkClientMethod = method
+	}
+}
+
+// WithRequireContentType rejects requests that are not sent as
+// application/json with a 415 before attempting to decode them.
+func WithRequireContentType() HandlerOption {
+	return func(o *handlerOptions) {
+		o.requireContentType = true
+	}
+}
+
+// WithMaxBodySize responds with a 413 when the request body is larger than n
+// bytes, reading no more than n+1 bytes of it.
+func WithMaxBodySize(n int64) HandlerOption {
+	return func(o *handlerOptions) {
+		o.maxBodySize = n
+	}
+}
+
+// WithBodyReadTimeout responds with a 408 when reading the request body takes
+// longer than d, so slow clients cannot hold on to the handler indefinitely.
+func WithBodyReadTimeout(d time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.bodyReadTimeout = d
+	}
+}
+
+// limitBodyRead applies the body read timeout to the body of r, returning the
+// reader to read it from. It sets a read deadline on the connection when the
+// server supports it, which also interrupts blocked reads, and otherwise
+// checks the deadline before every read. The returned function restores the
+// deadline of the server's ReadTimeout, if any.
+func (o handlerOptions) limitBodyRead(w http.ResponseWriter, r *http.Request) (io.Reader, func()) {
+	if o.bodyReadTimeout <= 0 {
+		return r.Body, func() {}
+	}
+	now := time.Now()
+	deadline := now.Add(o.bodyReadTimeout)
+	// The connection's read deadline cannot be read back, so the one the
+	// server's ReadTimeout set is restored afterwards as measured from now,
+	// slightly later than net/http set it. When it is the earlier of the two,
+	// the connection is left alone.
+	var previous time.Time
+	if srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server); ok && srv.ReadTimeout > 0 {
+		previous = now.Add(srv.ReadTimeout)
+		if previous.Before(deadline) {
+			return r.Body, func() {}
+		}
+	}
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(deadline); err == nil {
+		return r.Body, func() {
+			rc.SetReadDeadline(previous)
+		}
+	}
+	return deadlineReader{Reader: r.Body, deadline: deadline}, func() {}
+}
+
+// readBody calls read with the request body, honouring the body read timeout
+// and the maximum body size. Every handler reads the body through it, so both
+// options apply whatever the body's format.
+func (o handlerOptions) readBody(w http.ResponseWriter, r *http.Request, read func(body io.Reader) error) error {
+	body, clearReadDeadline := o.limitBodyRead(w, r)
+	defer clearReadDeadline()
+	if o.maxBodySize <= 0 {
+		return read(body)
+	}
+	limited := &io.LimitedReader{R: body, N: o.maxBodySize + 1}
+	err := read(limited)
+	if limited.N == 0 {
+		// The byte past the limit was read, so the body was truncated.
+		return errBodyTooLarge
+	}
+	return err
+}
+
+// decodeBody decodes the JSON request body into v with readBody.
+func (o handlerOptions) decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	return o.readBody(w, r, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(v)
+	})
+}
+
+func isBodyReadTimeout(err error) bool {
+	return errors.Is(err, errBodyReadTimeout) || errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+type deadlineReader struct {
+	io.Reader
+	deadline time.Time
+}
+
+func (r deadlineReader) Read(p []byte) (int, error) {
+	if time.Now().After(r.deadline) {
+		return 0, errBodyReadTimeout
+	}
+	return r.Reader.Read(p)
+}
+
+func (o handlerOptions) resolveStatus(statusCode int) int {
+	if o.successStatus != 0 && statusCode >= 200 && statusCode < 300 {
+		return o.successStatus
+	}
+	return statusCode
+}
+
+func (o handlerOptions) newEncoder(w io.Writer) *json.Encoder {
+	enc := o.newLineEncoder(w)
+	enc.SetIndent("", o.jsonIndent)
+	return enc
+}
+
+// newLineEncoder is newEncoder for formats with one value per line, such as
+// NDJSON and server-sent events, which the indent of WithJSONOptions would
+// break, so only its HTML escaping applies.
+func (o handlerOptions) newLineEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!o.jsonNoEscapeHTML)
+	return enc
+}
+
+func (o handlerOptions) runPreprocess(ctx context.Context, payload interface{}) error {
+	for _, fn := range o.preprocess {
+		if err := fn(ctx, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o handlerOptions) runPostprocess(ctx context.Context, resp interface{}, statusCode int) error {
+	for _, fn := range o.postprocess {
+		if err := fn(ctx, resp, statusCode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package ghttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMaxBodySize(t *testing.T) {
+	const limit = 7
+	h := NewJSONPayloadHandler(func(w http.ResponseWriter, r *http.Request, s string) (string, int) {
+		return s, http.StatusOK
+	}, WithMaxBodySize(limit))
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"at limit", `"abcde"`, http.StatusOK},
+		{"one byte over", `"abcdef"`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			if rec.Code != tt.want {
+				t.Errorf("status for %d byte body = %d, want %d", len(tt.body), rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMaxBodySizeAppliesToEveryHandler(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	const limit = 16
+	long := strings.Repeat("x", limit)
+	tests := []struct {
+		name string
+		h    http.Handler
+		body string
+	}{
+		{"FormPayloadHandler", NewFormPayloadHandler(func(w http.ResponseWriter, r *http.Request, in item) (item, int) {
+			return in, http.StatusOK
+		}, WithMaxBodySize(limit)), "name=" + long},
+		{"NDJSONPayloadHandler", NewNDJSONPayloadHandler(func(w http.ResponseWriter, r *http.Request, items []item) (int, int) {
+			return len(items), http.StatusOK
+		}, WithMaxBodySize(limit)), `{"name": "` + long + `"}`},
+		{"JSONPatchHandler", NewJSONPatchHandler(func(w http.ResponseWriter, r *http.Request) (item, int) {
+			return item{}, http.StatusOK
+		}, func(w http.ResponseWriter, r *http.Request, current item, ops []PatchOp) (item, int) {
+			return current, http.StatusOK
+		}, WithMaxBodySize(limit)), `[{"op": "add", "path": "/name", "value": "` + long + `"}]`},
+		{"JSONStreamPayloadHandler", NewJSONStreamPayloadHandler(func(w http.ResponseWriter, r *http.Request, in item, out chan<- item) {
+			out <- in
+		}, WithMaxBodySize(limit)), `{"name": "` + long + `"}`},
+		{"StreamingPayloadHandler", NewStreamingPayloadHandler(func(w http.ResponseWriter, r *http.Request, items <-chan item, errs <-chan error) (string, int) {
+			for range items {
+			}
+			if err := <-errs; errors.Is(err, errBodyTooLarge) {
+				return err.Error(), http.StatusRequestEntityTooLarge
+			}
+			return "", http.StatusOK
+		}, WithMaxBodySize(limit)), `[{"name": "` + long + `"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.name == "FormPayloadHandler" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			rec := httptest.NewRecorder()
+			tt.h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("status for %d byte body = %d, want %d", len(tt.body), rec.Code, http.StatusRequestEntityTooLarge)
+			}
+		})
+	}
+}
+
+func TestHandlersShareOptions(t *testing.T) {
+	type item struct {
+		HTML string `json:"html"`
+	}
+	panics := func() { panic("boom") }
+	tests := []struct {
+		name string
+		h    func(opts ...HandlerOption) http.Handler
+		body string
+	}{
+		{"JSONMultiResponseHandler", func(opts ...HandlerOption) http.Handler {
+			return NewMultiResponseHandler(func(w http.ResponseWriter, r *http.Request) (item, item, int) {
+				panics()
+				return item{}, item{}, http.StatusOK
+			}, opts...)
+		}, ""},
+		{"StreamingJSONHandler", func(opts ...HandlerOption) http.Handler {
+			return NewStreamingJSONHandler(func(w http.ResponseWriter, r *http.Request) (<-chan item, error) {
+				panics()
+				return nil, nil
+			}, opts...)
+		}, ""},
+		{"SSEHandler", func(opts ...HandlerOption) http.Handler {
+			return NewSSEHandler(func(w http.ResponseWriter, r *http.Request) (<-chan SSEEvent[item], error) {
+				panics()
+				return nil, nil
+			}, opts...)
+		}, ""},
+		{"NDJSONPayloadHandler", func(opts ...HandlerOption) http.Handler {
+			return NewNDJSONPayloadHandler(func(w http.ResponseWriter, r *http.Request, items []item) (item, int) {
+				panics()
+				return item{}, http.StatusOK
+			}, opts...)
+		}, `{"html": "a"}`},
+		{"JSONPatchHandler", func(opts ...HandlerOption) http.Handler {
+			return NewJSONPatchHandler(func(w http.ResponseWriter, r *http.Request) (item, int) {
+				panics()
+				return item{}, http.StatusOK
+			}, func(w http.ResponseWriter, r *http.Request, current item, ops []PatchOp) (item, int) {
+				return current, http.StatusOK
+			}, opts...)
+		}, `[]`},
+		{"JSONStreamPayloadHandler", func(opts ...HandlerOption) http.Handler {
+			return NewJSONStreamPayloadHandler(func(w http.ResponseWriter, r *http.Request, in item, out chan<- item) {
+				panics()
+			}, opts...)
+		}, `{}`},
+		{"StreamingPayloadHandler", func(opts ...HandlerOption) http.Handler {
+			return NewStreamingPayloadHandler(func(w http.ResponseWriter, r *http.Request, items <-chan item, errs <-chan error) (item, int) {
+				panics()
+				return item{}, http.StatusOK
+			}, opts...)
+		}, `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.h(WithFallbackResponse(http.StatusServiceUnavailable, item{HTML: "fallback"}))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			if rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("status = %d, want the fallback %d", rec.Code, http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+func TestStreamingHandlersHonourJSONOptions(t *testing.T) {
+	type item struct {
+		HTML string `json:"html"`
+	}
+	send := func() <-chan item {
+		items := make(chan item, 1)
+		items <- item{HTML: "<b>"}
+		close(items)
+		return items
+	}
+	tests := []struct {
+		name string
+		h    http.Handler
+		want string
+	}{
+		{"StreamingJSONHandler", NewStreamingJSONHandler(func(w http.ResponseWriter, r *http.Request) (<-chan item, error) {
+			return send(), nil
+		}, WithJSONOptions("  ", false)), "{\"html\":\"<b>\"}\n"},
+		{"SSEHandler", NewSSEHandler(func(w http.ResponseWriter, r *http.Request) (<-chan SSEEvent[item], error) {
+			events := make(chan SSEEvent[item], 1)
+			events <- SSEEvent[item]{Data: <-send()}
+			close(events)
+			return events, nil
+		}, WithJSONOptions("  ", false)), "data: {\"html\":\"<b>\"}\n\n:\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSunset(t *testing.T) {
+	h := NewJSONHandler(func(w http.ResponseWriter, r *http.Request) (string, http.Header, int) {
+		return "ok", nil, http.StatusOK
+	}, WithSunset(time.Date(2027, time.January, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := rec.Header().Get("Sunset"), "Thu, 31 Dec 2026 23:00:00 GMT"; got != want {
+		t.Errorf("Sunset = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,297 @@
+package ghttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchOp is a single JSON Patch (RFC 6902) operation.
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+	From  string      `json:"from,omitempty"`
+}
+
+// JSONPatchLoaderFunc loads the resource a patch applies to. A non-2xx status
+// code aborts the request with that status.
+type JSONPatchLoaderFunc[T any] func(http.ResponseWriter, *http.Request) (T, int)
+
+// JSONPatchHandlerFunc receives the loaded resource and the decoded patch
+// operations, typically applies them with ApplyPatch and stores the result.
+type JSONPatchHandlerFunc[T any] func(http.ResponseWriter, *http.Request, T, []PatchOp) (T, int)
+
+// JSONPatchHandler serves PATCH endpoints taking a JSON Patch document.
+type JSONPatchHandler[T any] struct {
+	loaderFunc  JSONPatchLoaderFunc[T]
+	handlerFunc JSONPatchHandlerFunc[T]
+	opts        handlerOptions
+}
+
+func NewJSONPatchHandler[T any](load JSONPatchLoaderFunc[T], fn JSONPatchHandlerFunc[T], opts ...HandlerOption) JSONPatchHandler[T] {
+	return JSONPatchHandler[T]{
+		loaderFunc:  load,
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+}
+
+func (h JSONPatchHandler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONPatchHandler[T]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var ops []PatchOp
+	if err := h.opts.decodeBody(w, r, &ops); err != nil {
+		h.opts.writeDecodeError(w, err)
+		return
+	}
+	current, code := h.loaderFunc(w, r)
+	if code < 200 || code >= 300 {
+		h.opts.writeError(w, code, http.StatusText(code))
+		return
+	}
+	resp, statusCode := h.handlerFunc(w, r, current, ops)
+	h.opts.writeJSON(w, statusCode, resp)
+}
+
+func (h JSONPatchHandler[T]) PayloadType() reflect.Type {
+	var v []PatchOp
+	return reflect.TypeOf(v)
+}
+
+func (h JSONPatchHandler[T]) ResponseType() reflect.Type {
+	var v T
+	return reflect.TypeOf(v)
+}
+
+// ApplyPatch applies ops to the JSON representation of target and decodes
+// the result back into it. target is left unchanged if any operation fails.
+func ApplyPatch[T any](target *T, ops []PatchOp) error {
+	doc, err := toJSONValue(target)
+	if err != nil {
+		return err
+	}
+	for i, op := range ops {
+		if doc, err = applyPatchOp(doc, op); err != nil {
+			return fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var patched T
+	if err := json.Unmarshal(b, &patched); err != nil {
+		return err
+	}
+	*target = patched
+	return nil
+}
+
+func applyPatchOp(doc interface{}, op PatchOp) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		value, err := toJSONValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+	case "remove":
+		return patchRemove(doc, path)
+	case "replace":
+		value, err := toJSONValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = patchRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := patchGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if doc, err = patchRemove(doc, from); err != nil {
+				return nil, err
+			}
+		} else if value, err = toJSONValue(value); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+	case "test":
+		expected, err := toJSONValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		actual, err := patchGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return nil, errors.New("test failed")
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// toJSONValue round-trips v through encoding/json, yielding a deep copy made
+// of maps, slices, strings, float64s, bools and nils.
+func toJSONValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// parsePointer splits a JSON pointer (RFC 6901) into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func parseIndex(token string, length int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || idx >= length {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return idx, nil
+}
+
+func patchGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch c := doc.(type) {
+		case map[string]interface{}:
+			child, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			doc = child
+		case []interface{}:
+			idx, err := parseIndex(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+			doc = c[idx]
+		default:
+			return nil, fmt.Errorf("cannot traverse into %q", token)
+		}
+	}
+	return doc, nil
+}
+
+// patchAt calls fn with the container holding the last token of path and
+// stores the container fn returns back into its parent.
+func patchAt(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		child, ok := c[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", path[0])
+		}
+		child, err := patchAt(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[path[0]] = child
+		return c, nil
+	case []interface{}:
+		idx, err := parseIndex(path[0], len(c))
+		if err != nil {
+			return nil, err
+		}
+		child, err := patchAt(c[idx], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = child
+		return c, nil
+	default:
+		return nil, fmt.Errorf("cannot traverse into %q", path[0])
+	}
+}
+
+func patchAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return patchAt(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, value), nil
+			}
+			idx, err := parseIndex(token, len(c)+1)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[idx+1:], c[idx:])
+			c[idx] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("cannot add %q", token)
+		}
+	})
+}
+
+func patchRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return patchAt(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			idx, err := parseIndex(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:idx], c[idx+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %q", token)
+		}
+	})
+}
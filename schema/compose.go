@@ -0,0 +1,33 @@
+// Package schema provides helpers for building Swagger schemas by hand.
+package schema
+
+import (
+	"github.com/go-openapi/spec"
+)
+
+// AllOf returns a schema matching all of schemas. Nil schemas are skipped.
+func AllOf(schemas ...*spec.Schema) *spec.Schema {
+	return &spec.Schema{SchemaProps: spec.SchemaProps{AllOf: values(schemas)}}
+}
+
+// OneOf returns a schema matching exactly one of schemas. Nil schemas are
+// skipped.
+func OneOf(schemas ...*spec.Schema) *spec.Schema {
+	return &spec.Schema{SchemaProps: spec.SchemaProps{OneOf: values(schemas)}}
+}
+
+// AnyOf returns a schema matching at least one of schemas. Nil schemas are
+// skipped.
+func AnyOf(schemas ...*spec.Schema) *spec.Schema {
+	return &spec.Schema{SchemaProps: spec.SchemaProps{AnyOf: values(schemas)}}
+}
+
+func values(schemas []*spec.Schema) []spec.Schema {
+	vs := make([]spec.Schema, 0, len(schemas))
+	for _, s := range schemas {
+		if s != nil {
+			vs = append(vs, *s)
+		}
+	}
+	return vs
+}
@@ -0,0 +1,127 @@
+package ghttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+// SSEEvent is a Server-Sent Event. Data is sent as JSON; ID and EventType are
+// left out of the event when empty. Neither may contain a carriage return or
+// line feed, which would end the field early and let the rest of the value
+// be read as fields of its own; an event breaking this ends the stream.
+type SSEEvent[O any] struct {
+	ID        string
+	EventType string
+	Data      O
+}
+
+// SSEHandlerFunc returns a channel of events, closed when there are no more.
+// An error is sent as a 500 before the stream starts.
+type SSEHandlerFunc[O any] func(http.ResponseWriter, *http.Request) (<-chan SSEEvent[O], error)
+
+// SSEHandler streams the events its handler function sends as
+// text/event-stream, flushing after each one. The stream ends with an empty
+// comment when the channel is closed or the request context is done.
+type SSEHandler[O any] struct {
+	handlerFunc SSEHandlerFunc[O]
+	opts        handlerOptions
+}
+
+func NewSSEHandler[O any](fn SSEHandlerFunc[O], opts ...HandlerOption) SSEHandler[O] {
+	return SSEHandler[O]{
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+}
+
+func (h SSEHandler[O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h SSEHandler[O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	events, err := h.handlerFunc(w, r)
+	if err != nil {
+		h.opts.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	// Tell proxies such as nginx not to buffer the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(h.opts.resolveStatus(http.StatusOK))
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	defer func() {
+		if _, err := io.WriteString(w, ":\n\n"); err != nil {
+			return
+		}
+		flush()
+	}()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := h.writeEvent(w, event); err != nil {
+				Logger().Error("writing event", slog.Any("error", err))
+				return
+			}
+			flush()
+		}
+	}
+}
+
+// writeEvent writes event with its data encoded on a single line. Nothing is
+// written for an event whose ID or EventType contain a line break.
+func (h SSEHandler[O]) writeEvent(w io.Writer, event SSEEvent[O]) error {
+	if strings.ContainsAny(event.ID, "\r\n") {
+		return fmt.Errorf("event id %q contains a line break", event.ID)
+	}
+	if strings.ContainsAny(event.EventType, "\r\n") {
+		return fmt.Errorf("event type %q contains a line break", event.EventType)
+	}
+	var data bytes.Buffer
+	if err := h.opts.newLineEncoder(&data).Encode(event.Data); err != nil {
+		return err
+	}
+	// Encode ends the data with the newline ending the field.
+	if _, err := fmt.Fprintf(w, "data: %s", data.Bytes()); err != nil {
+		return err
+	}
+	if event.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", event.ID); err != nil {
+			return err
+		}
+	}
+	if event.EventType != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", event.EventType); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ResponseType returns the type of the data of each event.
+func (h SSEHandler[O]) ResponseType() reflect.Type {
+	return reflect.TypeOf((*O)(nil)).Elem()
+}
+
+func (h SSEHandler[O]) Produces() []string {
+	return []string{eventStreamContentType}
+}
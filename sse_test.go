@@ -0,0 +1,35 @@
+package ghttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSSEHandlerRejectsLineBreaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		event SSEEvent[int]
+		want  string
+	}{
+		{"plain", SSEEvent[int]{ID: "1", EventType: "tick", Data: 1}, "data: 1\nid: 1\nevent: tick\n\ndata: 2\n\n:\n\n"},
+		{"line feed in id", SSEEvent[int]{ID: "1\nevent: admin", Data: 1}, ":\n\n"},
+		{"carriage return in event type", SSEEvent[int]{EventType: "tick\rdata: 2", Data: 1}, ":\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewSSEHandler(func(w http.ResponseWriter, r *http.Request) (<-chan SSEEvent[int], error) {
+				events := make(chan SSEEvent[int], 2)
+				events <- tt.event
+				events <- SSEEvent[int]{Data: 2}
+				close(events)
+				return events, nil
+			})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package ghttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// JSONStreamPayloadHandlerFunc sends response items on the channel and closes
+// it when done. It should stop early once the request context is done. The
+// items are written to the response while it runs, so it must not write to
+// the http.ResponseWriter itself.
+type JSONStreamPayloadHandlerFunc[I any, O any] func(http.ResponseWriter, *http.Request, I, chan<- O)
+
+// JSONStreamPayloadHandler encodes the items its handler function sends as a
+// JSON array, writing each as soon as it is received so large responses are
+// never held in memory.
+type JSONStreamPayloadHandler[I any, O any] struct {
+	handlerFunc JSONStreamPayloadHandlerFunc[I, O]
+	opts        handlerOptions
+}
+
+func NewJSONStreamPayloadHandler[I any, O any](fn JSONStreamPayloadHandlerFunc[I, O], opts ...HandlerOption) JSONStreamPayloadHandler[I, O] {
+	return JSONStreamPayloadHandler[I, O]{
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+}
+
+func (h JSONStreamPayloadHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h JSONStreamPayloadHandler[I, O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload I
+	if err := h.opts.decodeBody(w, r, &payload); err != nil {
+		h.opts.writeDecodeError(w, err)
+		return
+	}
+
+	items := make(chan O)
+	// stop ends the writer early if the handler function panics without
+	// closing items.
+	stop := make(chan struct{})
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		h.writeItems(w, items, stop)
+	}()
+	defer func() {
+		if p := recover(); p != nil {
+			close(stop)
+			<-written
+			panic(p)
+		}
+	}()
+	h.handlerFunc(w, r, payload, items)
+	<-written
+}
+
+// writeItems writes the items received until items is closed or stop is
+// closed as a JSON array.
+func (h JSONStreamPayloadHandler[I, O]) writeItems(w http.ResponseWriter, items <-chan O, stop <-chan struct{}) {
+	// Keep receiving after an error so the handler function never blocks.
+	defer func() {
+		for {
+			select {
+			case _, ok := <-items:
+				if !ok {
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	flusher, _ := w.(http.Flusher)
+	enc := h.opts.newEncoder(w)
+	// The response is started by the first item or by items being closed, so
+	// a panic before then still gets the fallback response.
+	started := false
+	for {
+		var item O
+		var ok bool
+		select {
+		case item, ok = <-items:
+		case <-stop:
+			return
+		}
+		sep := ","
+		if !started {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(h.opts.resolveStatus(http.StatusOK))
+			sep, started = "[", true
+		} else if !ok {
+			sep = ""
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return
+		}
+		if !ok {
+			break
+		}
+		if err := enc.Encode(item); err != nil {
+			Logger().Error("encoding response item", slog.Any("error", err))
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if _, err := io.WriteString(w, "]\n"); err != nil {
+		return
+	}
+}
+
+func (h JSONStreamPayloadHandler[I, O]) PayloadType() reflect.Type {
+	var v I
+	return reflect.TypeOf(v)
+}
+
+func (h JSONStreamPayloadHandler[I, O]) ResponseType() reflect.Type {
+	var v []O
+	return reflect.TypeOf(v)
+}
+
+// StreamingPayloadHandlerFunc receives request items on the first channel as
+// they are decoded. A decode error is sent on the error channel, after which
+// no more items are sent. Both channels are closed once the body is consumed.
+type StreamingPayloadHandlerFunc[I any, O any] func(http.ResponseWriter, *http.Request, <-chan I, <-chan error) (O, int)
+
+// StreamingPayloadHandler decodes a JSON array request body one item at a
+// time, so bulk imports are processed in constant memory.
+type StreamingPayloadHandler[I any, O any] struct {
+	handlerFunc StreamingPayloadHandlerFunc[I, O]
+	opts        handlerOptions
+}
+
+func NewStreamingPayloadHandler[I any, O any](fn StreamingPayloadHandlerFunc[I, O], opts ...HandlerOption) StreamingPayloadHandler[I, O] {
+	return StreamingPayloadHandler[I, O]{
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+}
+
+func (h StreamingPayloadHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h StreamingPayloadHandler[I, O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	items := make(chan I)
+	errs := make(chan error, 1)
+	// done stops the decoder if the handler function returns without
+	// receiving every item.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(items)
+		defer close(errs)
+		err := h.opts.readBody(w, r, func(body io.Reader) error {
+			return decodeJSONArray(body, items, done)
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	resp, statusCode := h.receive(w, r, items, errs, done, &wg)
+	h.opts.writeJSON(w, statusCode, resp)
+}
+
+// receive runs the handler function, then stops the decoder and waits for it
+// to exit, even if the handler function panics, as the body must not be read
+// once ServeHTTP returns.
+func (h StreamingPayloadHandler[I, O]) receive(w http.ResponseWriter, r *http.Request, items <-chan I, errs <-chan error, done chan<- struct{}, wg *sync.WaitGroup) (O, int) {
+	defer wg.Wait()
+	defer close(done)
+	return h.handlerFunc(w, r, items, errs)
+}
+
+// decodeJSONArray decodes the JSON array in body item by item, sending each
+// on items until the array ends, an error occurs or done is closed.
+func decodeJSONArray[I any](body io.Reader, items chan<- I, done <-chan struct{}) error {
+	dec := json.NewDecoder(body)
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected JSON array, got %v", tok)
+	}
+	for dec.More() {
+		var item I
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		select {
+		case items <- item:
+		case <-done:
+			return nil
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func (h StreamingPayloadHandler[I, O]) PayloadType() reflect.Type {
+	var v []I
+	return reflect.TypeOf(v)
+}
+
+func (h StreamingPayloadHandler[I, O]) ResponseType() reflect.Type {
+	var v O
+	return reflect.TypeOf(v)
+}
+
+// StreamingJSONHandlerFunc returns a channel of response items, closed when
+// there are no more. An error is sent as a 500 before anything is streamed.
+type StreamingJSONHandlerFunc[O any] func(http.ResponseWriter, *http.Request) (<-chan O, error)
+
+// StreamingJSONHandler writes the items its handler function sends as
+// newline-delimited JSON, flushing after each one.
+type StreamingJSONHandler[O any] struct {
+	handlerFunc StreamingJSONHandlerFunc[O]
+	opts        handlerOptions
+}
+
+func NewStreamingJSONHandler[O any](fn StreamingJSONHandlerFunc[O], opts ...HandlerOption) StreamingJSONHandler[O] {
+	return StreamingJSONHandler[O]{
+		handlerFunc: fn,
+		opts:        newHandlerOptions(opts...),
+	}
+}
+
+func (h StreamingJSONHandler[O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.opts.serve(w, r, h.serveHTTP)
+}
+
+func (h StreamingJSONHandler[O]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	items, err := h.handlerFunc(w, r)
+	if err != nil {
+		h.opts.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(h.opts.resolveStatus(http.StatusOK))
+	flusher, _ := w.(http.Flusher)
+	enc := h.opts.newLineEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case item, ok := <-items:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(item); err != nil {
+				Logger().Error("encoding response item", slog.Any("error", err))
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func (h StreamingJSONHandler[O]) ResponseType() reflect.Type {
+	return reflect.TypeOf((*O)(nil)).Elem()
+}
@@ -0,0 +1,47 @@
+package ghttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestStreamingJSONHandler(t *testing.T) {
+	type item struct {
+		N int `json:"n"`
+	}
+	h := NewStreamingJSONHandler(func(w http.ResponseWriter, r *http.Request) (<-chan item, error) {
+		items := make(chan item)
+		go func() {
+			defer close(items)
+			for n := 1; n <= 3; n++ {
+				items <- item{N: n}
+			}
+		}()
+		return items, nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", got)
+	}
+	if !rec.Flushed {
+		t.Error("items were not flushed")
+	}
+	var got []item
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var it item
+		if err := json.Unmarshal(scanner.Bytes(), &it); err != nil {
+			t.Fatalf("decoding line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, it)
+	}
+	if want := []item{{1}, {2}, {3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+// Package swaggerui serves Swagger UI for a document served by package
+// ghttp/chi. The page and the Swagger UI 5.17.14 distribution in dist are
+// embedded in the binary, so the page loads nothing from other hosts.
+//
+//	r := chi.NewRouter()
+//	r.Get("/users/{id}", getUser.ServeHTTP)
+//	r.Get("/docs/swagger.json", ghttpchi.HandlerFunc(r))
+//	swaggerui.RegisterHandler(r, "/docs/swagger.json", "/docs")
+package swaggerui
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	//go:embed index.html
+	indexHTML string
+	// The assets are named so that the build fails if any is missing.
+	//
+	//go:embed dist/swagger-ui.css dist/swagger-ui-bundle.js dist/swagger-ui-standalone-preset.js
+	dist embed.FS
+
+	indexTemplate = template.Must(template.New("swagger-ui").Parse(indexHTML))
+)
+
+// RegisterHandler serves Swagger UI at uiPath on r, loading the document from
+// specPath.
+func RegisterHandler(r chi.Router, specPath string, uiPath string) {
+	h := Handler(specPath, uiPath)
+	r.Get(uiPath, h)
+	r.Get(strings.TrimSuffix(uiPath, "/")+"/assets/*", h)
+}
+
+// Handler serves the Swagger UI page loading the document at specURL, and
+// the embedded assets it references. It must be routed both at uiPath and at
+// uiPath+"/assets/*".
+func Handler(specURL string, uiPath string) http.HandlerFunc {
+	assets, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic("swaggerui: " + err.Error())
+	}
+	assetsURL := strings.TrimSuffix(uiPath, "/") + "/assets"
+	var page bytes.Buffer
+	if err := indexTemplate.Execute(&page, struct{ SpecURL, AssetsURL string }{specURL, assetsURL}); err != nil {
+		panic("swaggerui: rendering index.html: " + err.Error())
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		if name := chi.URLParam(req, "*"); name != "" {
+			http.ServeFileFS(w, req, assets, name)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(page.Bytes())
+	}
+}
@@ -0,0 +1,33 @@
+package swaggerui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRegisterHandlerServesEmbeddedAssets(t *testing.T) {
+	r := chi.NewRouter()
+	RegisterHandler(r, "/docs/swagger.json", "/docs/ui")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/ui", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("page status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	page := rec.Body.String()
+	for _, asset := range []string{"swagger-ui.css", "swagger-ui-bundle.js", "swagger-ui-standalone-preset.js"} {
+		path := "/docs/ui/assets/" + asset
+		if !strings.Contains(page, path) {
+			t.Errorf("page does not reference %s", path)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Errorf("%s: status %d with %d bytes, want the embedded asset", path, rec.Code, rec.Body.Len())
+		}
+	}
+}